@@ -0,0 +1,57 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// newAuditFile opens filename for appending, it is closed along with res.
+func newAuditFile(filename string, res *resources) (zapcore.WriteSyncer, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	res.closers = append(res.closers, f)
+	return zapcore.Lock(f), nil
+}
+
+// auditCore syncs the file after every entry at ErrorLevel or above, so that
+// the entries which matter most survive a crash of the machine.
+type auditCore struct {
+	zapcore.Core
+	file zapcore.WriteSyncer
+}
+
+func newAuditCore(core zapcore.Core, file zapcore.WriteSyncer) *auditCore {
+	return &auditCore{Core: core, file: file}
+}
+
+// With adds fields to a copy of the core, the copy shares the file.
+func (c *auditCore) With(fields []zapcore.Field) zapcore.Core {
+	return &auditCore{Core: c.Core.With(fields), file: c.file}
+}
+
+// Check adds the core if the entry is enabled.
+func (c *auditCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry and syncs the file if the entry is at ErrorLevel or
+// above.
+func (c *auditCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if err := c.Core.Write(ent, fields); err != nil {
+		return err
+	}
+	if ent.Level >= zapcore.ErrorLevel {
+		return c.file.Sync()
+	}
+	return nil
+}
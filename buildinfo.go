@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"runtime/debug"
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// BuildInfo describes the build of the program, which correlates the logs with
+// the releases.
+type BuildInfo struct {
+	// Version is attached as the "version" field.
+	Version string `json:"version,omitempty" yaml:"version,omitempty"`
+
+	// Commit is attached as the "commit" field.
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+
+	// CommitTime is attached as the "commit_time" field.
+	CommitTime string `json:"commit_time,omitempty" yaml:"commit_time,omitempty"`
+
+	// BuildTime is attached as the "build_time" field. It is never read from
+	// the binary, which doesn't record when it was built.
+	BuildTime string `json:"build_time,omitempty" yaml:"build_time,omitempty"`
+}
+
+// fields returns the fields of the info which are set.
+func (b BuildInfo) fields() []zap.Field {
+	var fields []zap.Field
+	if b.Version != "" {
+		fields = append(fields, zap.String("version", b.Version))
+	}
+	if b.Commit != "" {
+		fields = append(fields, zap.String("commit", b.Commit))
+	}
+	if b.CommitTime != "" {
+		fields = append(fields, zap.String("commit_time", b.CommitTime))
+	}
+	if b.BuildTime != "" {
+		fields = append(fields, zap.String("build_time", b.BuildTime))
+	}
+	return fields
+}
+
+var (
+	buildInfoOnce sync.Once
+	buildInfoVal  BuildInfo
+)
+
+// readBuildInfo returns the cached info embedded in the binary by the go
+// command: the version of the main module and the revision and time of the
+// commit it is built from. A field is empty if it is unknown, like the version
+// of a binary built from a local checkout.
+func readBuildInfo() BuildInfo {
+	buildInfoOnce.Do(func() {
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		if info.Main.Version != "(devel)" {
+			buildInfoVal.Version = info.Main.Version
+		}
+		readVCSInfo(info, &buildInfoVal)
+	})
+	return buildInfoVal
+}
@@ -0,0 +1,10 @@
+//go:build !go1.18
+// +build !go1.18
+
+package logger
+
+import "runtime/debug"
+
+// readVCSInfo does nothing, since the version control settings are embedded
+// in the binaries since Go 1.18.
+func readVCSInfo(*debug.BuildInfo, *BuildInfo) {}
@@ -0,0 +1,19 @@
+//go:build go1.18
+// +build go1.18
+
+package logger
+
+import "runtime/debug"
+
+// readVCSInfo fills the commit and its time from the version control settings
+// of info.
+func readVCSInfo(info *debug.BuildInfo, b *BuildInfo) {
+	for _, setting := range info.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			b.Commit = setting.Value
+		case "vcs.time":
+			b.CommitTime = setting.Value
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package logger
+
+// The *If methods log only if the condition holds, which saves the if
+// statements around the logging calls:
+//
+//	logger.WarnIf(retries > 3, "retrying", logger.Int("retries", retries))
+//
+// The arguments are evaluated by the caller anyway, the *IfFunc methods build
+// them in a func instead, which only runs if the condition holds and the level
+// is enabled.
+
+// DebugIf logs a message with the given fields if cond is true.
+func (l Logger) DebugIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		l.sugared.Desugar().Debug(msg, fields...)
+	}
+}
+
+// DebugIfFunc logs the message and fields returned by fn if cond is true.
+func (l Logger) DebugIfFunc(cond bool, fn func() (string, []Field)) {
+	if cond && l.Enabled(DebugLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Debug(msg, fields...)
+	}
+}
+
+// InfoIf logs a message with the given fields if cond is true.
+func (l Logger) InfoIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		l.sugared.Desugar().Info(msg, fields...)
+	}
+}
+
+// InfoIfFunc logs the message and fields returned by fn if cond is true.
+func (l Logger) InfoIfFunc(cond bool, fn func() (string, []Field)) {
+	if cond && l.Enabled(InfoLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Info(msg, fields...)
+	}
+}
+
+// WarnIf logs a message with the given fields if cond is true.
+func (l Logger) WarnIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		l.sugared.Desugar().Warn(msg, fields...)
+	}
+}
+
+// WarnIfFunc logs the message and fields returned by fn if cond is true.
+func (l Logger) WarnIfFunc(cond bool, fn func() (string, []Field)) {
+	if cond && l.Enabled(WarnLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Warn(msg, fields...)
+	}
+}
+
+// ErrorIf logs a message with the given fields if cond is true.
+func (l Logger) ErrorIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		l.sugared.Desugar().Error(msg, fields...)
+	}
+}
+
+// ErrorIfFunc logs the message and fields returned by fn if cond is true.
+func (l Logger) ErrorIfFunc(cond bool, fn func() (string, []Field)) {
+	if cond && l.Enabled(ErrorLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Error(msg, fields...)
+	}
+}
+
+// DebugIf logs a message with the given fields if cond is true.
+func DebugIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		StandardLogger().sugared.Desugar().Debug(msg, fields...)
+	}
+}
+
+// DebugIfFunc logs the message and fields returned by fn if cond is true.
+func DebugIfFunc(cond bool, fn func() (string, []Field)) {
+	if l := StandardLogger(); cond && l.Enabled(DebugLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Debug(msg, fields...)
+	}
+}
+
+// InfoIf logs a message with the given fields if cond is true.
+func InfoIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		StandardLogger().sugared.Desugar().Info(msg, fields...)
+	}
+}
+
+// InfoIfFunc logs the message and fields returned by fn if cond is true.
+func InfoIfFunc(cond bool, fn func() (string, []Field)) {
+	if l := StandardLogger(); cond && l.Enabled(InfoLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Info(msg, fields...)
+	}
+}
+
+// WarnIf logs a message with the given fields if cond is true.
+func WarnIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		StandardLogger().sugared.Desugar().Warn(msg, fields...)
+	}
+}
+
+// WarnIfFunc logs the message and fields returned by fn if cond is true.
+func WarnIfFunc(cond bool, fn func() (string, []Field)) {
+	if l := StandardLogger(); cond && l.Enabled(WarnLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Warn(msg, fields...)
+	}
+}
+
+// ErrorIf logs a message with the given fields if cond is true.
+func ErrorIf(cond bool, msg string, fields ...Field) {
+	if cond {
+		StandardLogger().sugared.Desugar().Error(msg, fields...)
+	}
+}
+
+// ErrorIfFunc logs the message and fields returned by fn if cond is true.
+func ErrorIfFunc(cond bool, fn func() (string, []Field)) {
+	if l := StandardLogger(); cond && l.Enabled(ErrorLevel) {
+		msg, fields := fn()
+		l.sugared.Desugar().Error(msg, fields...)
+	}
+}
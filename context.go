@@ -0,0 +1,45 @@
+package logger
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx which carries the logger.
+func (l Logger) WithContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger carried by ctx, or the standard logger if
+// there is none.
+func FromContext(ctx context.Context) Logger {
+	if l, ok := ctx.Value(contextKey{}).(Logger); ok {
+		return l
+	}
+	return StandardLogger()
+}
+
+// WithSpan adds the trace and span ids of the OpenTelemetry span carried by
+// ctx to the logging context, as the "trace_id" and "span_id" fields, which
+// correlates the logs with the traces. The logger is returned unchanged if
+// ctx carries no valid span.
+func (l Logger) WithSpan(ctx context.Context) Logger {
+	spanCtx := trace.SpanContextFromContext(ctx)
+	if !spanCtx.IsValid() {
+		return l
+	}
+	return l.WithFields(
+		zap.String("trace_id", spanCtx.TraceID().String()),
+		zap.String("span_id", spanCtx.SpanID().String()),
+	)
+}
+
+// WithSpan adds the trace and span ids of the span carried by ctx to the
+// logging context of the standard logger.
+func WithSpan(ctx context.Context) Logger {
+	return StandardLogger().WithSpan(ctx)
+}
@@ -0,0 +1,81 @@
+package logger
+
+import (
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// wrapCore applies the wrappers which rewrite fields to a core writing to a
+// single destination. They can't wrap a tee since a tee writes to all of its
+// cores without checking their levels again.
+func wrapCore(core zapcore.Core, opt Options) zapcore.Core {
+	if len(opt.RedactKeys) > 0 {
+		core = newRedactCore(core, opt.RedactKeys)
+	}
+	if opt.MaxFieldBytes > 0 {
+		core = newTruncateCore(core, opt.MaxFieldBytes)
+	}
+	return core
+}
+
+const redactedValue = "***"
+
+// redactCore replaces the values of the fields whose keys match case
+// insensitively with "***".
+type redactCore struct {
+	zapcore.Core
+	keys map[string]struct{}
+}
+
+func newRedactCore(core zapcore.Core, keys []string) *redactCore {
+	c := &redactCore{Core: core, keys: make(map[string]struct{}, len(keys))}
+	for _, key := range keys {
+		c.keys[strings.ToLower(key)] = struct{}{}
+	}
+	return c
+}
+
+// With adds the redacted fields to the core.
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redact(fields)), keys: c.keys}
+}
+
+// Check adds the core itself rather than the wrapped one, so that Write gets
+// the fields.
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the redacted fields.
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	return c.Core.Write(ent, c.redact(fields))
+}
+
+// redact returns fields with the matched values replaced, fields is copied
+// rather than modified in place since it is owned by the caller.
+func (c *redactCore) redact(fields []zapcore.Field) []zapcore.Field {
+	var redacted []zapcore.Field
+	for i, f := range fields {
+		if f.Type == zapcore.NamespaceType {
+			continue
+		}
+		if _, ok := c.keys[strings.ToLower(f.Key)]; !ok {
+			continue
+		}
+		if redacted == nil {
+			redacted = make([]zapcore.Field, len(fields))
+			copy(redacted, fields)
+		}
+		redacted[i] = zap.String(f.Key, redactedValue)
+	}
+
+	if redacted == nil {
+		return fields
+	}
+	return redacted
+}
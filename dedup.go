@@ -0,0 +1,183 @@
+package logger
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// dedupCacheSize is the maximum number of messages tracked at once, the least
+// recently logged one is dropped for a new one beyond that.
+const dedupCacheSize = 1024
+
+// dedupCore drops the entries repeating the level and message of an entry
+// written within the window, and logs how many were dropped once the window
+// closes.
+type dedupCore struct {
+	zapcore.Core
+	dedup *deduper
+}
+
+// newDedupCore wraps core and starts the goroutine logging the summaries,
+// which is stopped by closing the returned closer.
+func newDedupCore(core zapcore.Core, window time.Duration) (*dedupCore, closerFunc) {
+	d := &deduper{
+		window:  window,
+		size:    dedupCacheSize,
+		entries: make(map[dedupKey]*list.Element),
+		lru:     list.New(),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go d.run()
+
+	// stopping is idempotent, so that a closed logger can be closed again.
+	var once sync.Once
+	stop := func() error {
+		once.Do(func() { close(d.stop) })
+		<-d.done
+		return nil
+	}
+	return &dedupCore{Core: core, dedup: d}, stop
+}
+
+// With adds fields to the core, the deduper is shared with the derived core.
+func (c *dedupCore) With(fields []zapcore.Field) zapcore.Core {
+	return &dedupCore{Core: c.Core.With(fields), dedup: c.dedup}
+}
+
+// Check drops the entry if it repeats one within the window.
+func (c *dedupCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) || !c.dedup.allow(ent, c.Core) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+type dedupKey struct {
+	level   zapcore.Level
+	message string
+}
+
+type dedupEntry struct {
+	key      dedupKey
+	start    time.Time
+	repeated int
+
+	// core is the one of the logger which dropped the last repetition, the
+	// summary is written through it to carry the fields of that logger.
+	core zapcore.Core
+}
+
+type deduper struct {
+	window time.Duration
+	size   int
+
+	mu      sync.Mutex
+	entries map[dedupKey]*list.Element
+	lru     *list.List // of *dedupEntry, the most recently logged first.
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// allow reports whether ent is the first of its kind within the window, core
+// is the one the entry would be written to.
+func (d *deduper) allow(ent zapcore.Entry, core zapcore.Core) bool {
+	key := dedupKey{level: ent.Level, message: ent.Message}
+
+	d.mu.Lock()
+	// the entries dropped here are summarized after unlocking.
+	var closed []*dedupEntry
+	if elem, ok := d.entries[key]; ok {
+		e := elem.Value.(*dedupEntry)
+		if ent.Time.Sub(e.start) < d.window {
+			e.repeated++
+			e.core = core
+			d.lru.MoveToFront(elem)
+			d.mu.Unlock()
+			return false
+		}
+		// the window is over while its summary is not logged yet.
+		d.lru.Remove(elem)
+		delete(d.entries, key)
+		closed = append(closed, e)
+	}
+	if d.lru.Len() >= d.size {
+		e := d.lru.Remove(d.lru.Back()).(*dedupEntry)
+		delete(d.entries, e.key)
+		closed = append(closed, e)
+	}
+	d.entries[key] = d.lru.PushFront(&dedupEntry{key: key, start: ent.Time, core: core})
+	d.mu.Unlock()
+
+	for _, e := range closed {
+		d.summarize(e)
+	}
+	return true
+}
+
+// run logs the summaries of the closed windows until the deduper is stopped,
+// the pending summaries are logged before it returns.
+func (d *deduper) run() {
+	defer close(d.done)
+
+	// check twice per window, so that a summary is late by half a window at
+	// most.
+	interval := d.window / 2
+	if interval <= 0 {
+		interval = d.window
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case now := <-ticker.C:
+			d.flush(now, false)
+		case <-d.stop:
+			d.flush(time.Now(), true)
+			return
+		}
+	}
+}
+
+// flush drops the entries whose window is closed, or all of them if all is
+// true, and logs their summaries.
+func (d *deduper) flush(now time.Time, all bool) {
+	var closed []*dedupEntry
+
+	d.mu.Lock()
+	for key, elem := range d.entries {
+		e := elem.Value.(*dedupEntry)
+		if all || now.Sub(e.start) >= d.window {
+			d.lru.Remove(elem)
+			delete(d.entries, key)
+			closed = append(closed, e)
+		}
+	}
+	d.mu.Unlock()
+
+	for _, e := range closed {
+		d.summarize(e)
+	}
+}
+
+// summarize logs the message of e again with the number of the entries
+// dropped, if any.
+func (d *deduper) summarize(e *dedupEntry) {
+	if e.repeated == 0 {
+		return
+	}
+	ent := zapcore.Entry{
+		Level:   e.key.level,
+		Time:    time.Now(),
+		Message: e.key.message,
+	}
+	if ce := e.core.Check(ent, nil); ce != nil {
+		ce.Write(zap.Int("repeated", e.repeated))
+	}
+}
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestDedupConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, DedupWindow: time.Hour})
+
+	logConcurrently(8, 100, func(g, i int) {
+		l.Info("repeated")
+		if i == 0 {
+			l.Warn("repeated")
+		}
+	})
+	// the pending summaries are logged on closing.
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	type key struct {
+		level    string
+		repeated float64
+	}
+	counts := make(map[key]int)
+	for _, entry := range decodeLines(t, &buf) {
+		if entry["msg"] != "repeated" {
+			t.Errorf("unexpected entry %v", entry)
+			continue
+		}
+		repeated, _ := entry["repeated"].(float64)
+		counts[key{entry["level"].(string), repeated}]++
+	}
+
+	want := map[key]int{
+		{"INFO", 0}:   1,
+		{"INFO", 799}: 1,
+		{"WARN", 0}:   1,
+		{"WARN", 7}:   1,
+	}
+	if len(counts) != len(want) {
+		t.Errorf("got %v, want %v", counts, want)
+	}
+	for k, n := range want {
+		if counts[k] != n {
+			t.Errorf("%v logged %d times, want %d", k, counts[k], n)
+		}
+	}
+}
+
+func TestDedupEviction(t *testing.T) {
+	obs, logs := observer.New(zapcore.DebugLevel)
+	core, stop := newDedupCore(obs, time.Hour)
+	defer stop()
+	core.dedup.size = 2
+
+	logger := zap.New(core)
+	for _, msg := range []string{"a", "a", "b", "c"} {
+		logger.Info(msg)
+	}
+
+	// c evicts a, the least recently logged, whose summary is logged early.
+	var got []string
+	for _, e := range logs.AllUntimed() {
+		if repeated, ok := e.ContextMap()["repeated"]; ok {
+			got = append(got, fmt.Sprintf("%s repeated %v", e.Message, repeated))
+			continue
+		}
+		got = append(got, e.Message)
+	}
+	want := []string{"a", "b", "a repeated 1", "c"}
+	if len(got) != len(want) {
+		t.Fatalf("got %q, want %q", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %q, want %q", got, want)
+			break
+		}
+	}
+}
+
+func TestDedupSummaryDerived(t *testing.T) {
+	var buf bytes.Buffer
+	var writes int32
+	l := New(Options{
+		Writer:      &buf,
+		ServiceName: "api",
+		DedupWindow: time.Hour,
+		OnWrite:     func(Level) { atomic.AddInt32(&writes, 1) },
+	})
+
+	child := l.With("request_id", "abc")
+	for i := 0; i < 3; i++ {
+		child.Info("repeated")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want the entry and the summary:\n%s", len(lines), buf.String())
+	}
+	summary := lines[1]
+	if summary["repeated"] != 2.0 || summary["request_id"] != "abc" || summary["service"] != "api" {
+		t.Errorf("summary = %v, want 2 repeated with the fields of the child", summary)
+	}
+	if writes != 2 {
+		t.Errorf("OnWrite called %d times, want 2", writes)
+	}
+}
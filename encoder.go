@@ -0,0 +1,351 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+const defaultTimeFormat = "2006-01-02 15:04:05.000"
+
+// The special values of Options.TimeFormat which encode timestamps as numbers
+// since the Unix epoch.
+const (
+	// EpochTimeFormat encodes timestamps as floating-point seconds.
+	EpochTimeFormat = "epoch"
+
+	// EpochMillisTimeFormat encodes timestamps as integer milliseconds.
+	EpochMillisTimeFormat = "epochmillis"
+
+	// EpochNanosTimeFormat encodes timestamps as integer nanoseconds.
+	EpochNanosTimeFormat = "epochnanos"
+)
+
+// Format is the encoding of logs.
+type Format string
+
+const (
+	// JSONFormat encodes logs as JSON objects.
+	JSONFormat Format = "json"
+
+	// ConsoleFormat encodes logs in a human-friendly format, where the fields
+	// are appended as a JSON object.
+	ConsoleFormat Format = "console"
+
+	// LogfmtFormat encodes logs as space-separated key=value pairs, where the
+	// values containing spaces, quotes or equals signs are quoted.
+	LogfmtFormat Format = "logfmt"
+)
+
+// LevelEncoder determines how levels are encoded.
+type LevelEncoder int8
+
+const (
+	// CapitalLevelEncoder encodes levels as upper-case strings, like INFO. It
+	// is the default.
+	CapitalLevelEncoder LevelEncoder = iota
+
+	// LowercaseLevelEncoder encodes levels as lower-case strings, like info.
+	LowercaseLevelEncoder
+
+	// CapitalColorLevelEncoder encodes levels as upper-case strings with ANSI
+	// colors.
+	CapitalColorLevelEncoder
+
+	// LowercaseColorLevelEncoder encodes levels as lower-case strings with
+	// ANSI colors.
+	LowercaseColorLevelEncoder
+)
+
+// zap returns the zapcore encoder of the level encoder.
+func (e LevelEncoder) zap() zapcore.LevelEncoder {
+	switch e {
+	case LowercaseLevelEncoder:
+		return zapcore.LowercaseLevelEncoder
+	case CapitalColorLevelEncoder:
+		return zapcore.CapitalColorLevelEncoder
+	case LowercaseColorLevelEncoder:
+		return zapcore.LowercaseColorLevelEncoder
+	default:
+		return zapcore.CapitalLevelEncoder
+	}
+}
+
+// colored returns the colored variant of the level encoder.
+func (e LevelEncoder) colored() LevelEncoder {
+	switch e {
+	case CapitalLevelEncoder:
+		return CapitalColorLevelEncoder
+	case LowercaseLevelEncoder:
+		return LowercaseColorLevelEncoder
+	default:
+		return e
+	}
+}
+
+// DurationFormat determines how durations are encoded.
+type DurationFormat int8
+
+const (
+	// SecondsDurationFormat encodes durations as floating-point seconds. It is
+	// the default.
+	SecondsDurationFormat DurationFormat = iota
+
+	// MillisDurationFormat encodes durations as floating-point milliseconds.
+	MillisDurationFormat
+
+	// NanosDurationFormat encodes durations as integer nanoseconds.
+	NanosDurationFormat
+
+	// StringDurationFormat encodes durations as strings, like "1.5s".
+	StringDurationFormat
+)
+
+// zap returns the zapcore encoder of the duration format.
+func (f DurationFormat) zap() zapcore.DurationEncoder {
+	switch f {
+	case MillisDurationFormat:
+		return zapcore.MillisDurationEncoder
+	case NanosDurationFormat:
+		return zapcore.NanosDurationEncoder
+	case StringDurationFormat:
+		return zapcore.StringDurationEncoder
+	default:
+		return zapcore.SecondsDurationEncoder
+	}
+}
+
+// EncoderKeys overrides the keys of the built-in fields, empty keys keep the
+// defaults.
+type EncoderKeys struct {
+	// TimeKey defaults to "ts".
+	TimeKey string `json:"time_key,omitempty" yaml:"time_key,omitempty"`
+
+	// LevelKey defaults to "level".
+	LevelKey string `json:"level_key,omitempty" yaml:"level_key,omitempty"`
+
+	// MessageKey defaults to "msg".
+	MessageKey string `json:"message_key,omitempty" yaml:"message_key,omitempty"`
+
+	// CallerKey defaults to "caller".
+	CallerKey string `json:"caller_key,omitempty" yaml:"caller_key,omitempty"`
+
+	// NameKey defaults to "logger".
+	NameKey string `json:"name_key,omitempty" yaml:"name_key,omitempty"`
+
+	// StacktraceKey defaults to "stacktrace".
+	StacktraceKey string `json:"stacktrace_key,omitempty" yaml:"stacktrace_key,omitempty"`
+
+	// FunctionKey defaults to "func", it only applies if WithFunction is true.
+	FunctionKey string `json:"function_key,omitempty" yaml:"function_key,omitempty"`
+}
+
+// apply overrides the keys of encoderConfig.
+func (k EncoderKeys) apply(encoderConfig *zapcore.EncoderConfig) {
+	for _, key := range []struct {
+		dst *string
+		val string
+	}{
+		{&encoderConfig.TimeKey, k.TimeKey},
+		{&encoderConfig.LevelKey, k.LevelKey},
+		{&encoderConfig.MessageKey, k.MessageKey},
+		{&encoderConfig.CallerKey, k.CallerKey},
+		{&encoderConfig.NameKey, k.NameKey},
+		{&encoderConfig.StacktraceKey, k.StacktraceKey},
+		{&encoderConfig.FunctionKey, k.FunctionKey},
+	} {
+		if key.val != "" {
+			*key.dst = key.val
+		}
+	}
+}
+
+// newEncoderConfig returns the encoder config shared by all the outputs.
+func newEncoderConfig(opt Options) zapcore.EncoderConfig {
+	// the production config carries the keys of all the built-in fields, the
+	// name of the logger included.
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.EncodeTime = newTimeEncoder(opt.TimeFormat, opt.UTC)
+	encoderConfig.EncodeLevel = opt.LevelEncoder.zap()
+	encoderConfig.EncodeDuration = opt.DurationFormat.zap()
+	if opt.FullCaller {
+		encoderConfig.EncodeCaller = zapcore.FullCallerEncoder
+	}
+	if opt.WithFunction {
+		encoderConfig.FunctionKey = "func"
+	}
+	opt.EncoderKeys.apply(&encoderConfig)
+	if !opt.WithFunction {
+		encoderConfig.FunctionKey = ""
+	}
+	if opt.DisableTime {
+		encoderConfig.TimeKey = ""
+	}
+	return encoderConfig
+}
+
+// newTimeEncoder returns the time encoder for the given format, which is
+// either a Go time layout or one of the epoch sentinels.
+func newTimeEncoder(format string, utc bool) zapcore.TimeEncoder {
+	switch format {
+	case EpochTimeFormat:
+		return zapcore.EpochTimeEncoder
+	case EpochMillisTimeFormat:
+		// zapcore.EpochMillisTimeEncoder encodes a float, which most of the
+		// ingestion pipelines don't expect.
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendInt64(t.UnixNano() / int64(time.Millisecond))
+		}
+	case EpochNanosTimeFormat:
+		return zapcore.EpochNanosTimeEncoder
+	case "":
+		format = defaultTimeFormat
+	}
+
+	if utc {
+		return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+			enc.AppendString(t.UTC().Format(format))
+		}
+	}
+	return func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Local().Format(format))
+	}
+}
+
+var bufferPool = buffer.NewPool()
+
+// prettyEncoder indents the entries encoded by a JSON encoder.
+type prettyEncoder struct {
+	zapcore.Encoder
+}
+
+func newPrettyEncoder(enc zapcore.Encoder) zapcore.Encoder {
+	return prettyEncoder{Encoder: enc}
+}
+
+// Clone copies the encoder.
+func (e prettyEncoder) Clone() zapcore.Encoder {
+	return prettyEncoder{Encoder: e.Encoder.Clone()}
+}
+
+// EncodeEntry encodes the entry and indents the JSON object.
+func (e prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	var b bytes.Buffer
+	if err := json.Indent(&b, bytes.TrimRight(buf.Bytes(), "\n"), "", "  "); err != nil {
+		return nil, err
+	}
+	out := bufferPool.Get()
+	out.Write(b.Bytes())
+	out.AppendByte('\n')
+	return out, nil
+}
+
+// sortedEncoder sorts the fields of the entries encoded by a JSON encoder. The
+// entries are decoded and encoded again, encoding/json sorts the keys of the
+// nested objects.
+type sortedEncoder struct {
+	zapcore.Encoder
+	builtin    map[string]bool
+	lineEnding string
+}
+
+func newSortedEncoder(enc zapcore.Encoder, encoderConfig zapcore.EncoderConfig) zapcore.Encoder {
+	builtin := make(map[string]bool)
+	for _, key := range []string{
+		encoderConfig.TimeKey,
+		encoderConfig.LevelKey,
+		encoderConfig.NameKey,
+		encoderConfig.CallerKey,
+		encoderConfig.FunctionKey,
+		encoderConfig.MessageKey,
+		encoderConfig.StacktraceKey,
+	} {
+		if key != "" {
+			builtin[key] = true
+		}
+	}
+	lineEnding := encoderConfig.LineEnding
+	if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	return sortedEncoder{Encoder: enc, builtin: builtin, lineEnding: lineEnding}
+}
+
+// Clone copies the encoder.
+func (e sortedEncoder) Clone() zapcore.Encoder {
+	return sortedEncoder{Encoder: e.Encoder.Clone(), builtin: e.builtin, lineEnding: e.lineEnding}
+}
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+// EncodeEntry encodes the entry with the built-in fields first and the others
+// sorted by key.
+func (e sortedEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	buf, err := e.Encoder.EncodeEntry(ent, fields)
+	if err != nil {
+		return nil, err
+	}
+	defer buf.Free()
+
+	dec := json.NewDecoder(bytes.NewReader(buf.Bytes()))
+	dec.UseNumber()
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	var builtin, members []jsonMember
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		m := jsonMember{key: key.(string)}
+		if err := dec.Decode(&m.value); err != nil {
+			return nil, err
+		}
+		if e.builtin[m.key] {
+			builtin = append(builtin, m)
+		} else {
+			members = append(members, m)
+		}
+	}
+	sort.SliceStable(members, func(i, j int) bool { return members[i].key < members[j].key })
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	b.WriteByte('{')
+	for i, m := range append(builtin, members...) {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		if err := enc.Encode(m.key); err != nil {
+			return nil, err
+		}
+		b.Truncate(b.Len() - 1) // the newline appended by Encode
+		b.WriteByte(':')
+		if err := enc.Encode(m.value); err != nil {
+			return nil, err
+		}
+		b.Truncate(b.Len() - 1)
+	}
+	b.WriteByte('}')
+
+	out := bufferPool.Get()
+	out.Write(b.Bytes())
+	out.AppendString(e.lineEnding)
+	return out, nil
+}
@@ -0,0 +1,111 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// FastLogger is the companion of Logger for latency-critical code, it wraps
+// zap.Logger directly and only takes strongly-typed fields, which avoids the
+// reflection and allocations of the sugared API. It shares the outputs and
+// the level with the Logger it comes from.
+type FastLogger struct {
+	logger *zap.Logger
+	level  zap.AtomicLevel
+	res    *resources
+}
+
+// NewFast returns a FastLogger built from the options in the same way as New,
+// it panics if the log file can not be prepared.
+func NewFast(opt Options) FastLogger {
+	return New(opt).Fast()
+}
+
+// Fast returns the FastLogger sharing the outputs and the level of the logger.
+func (l Logger) Fast() FastLogger {
+	return FastLogger{logger: l.sugared.Desugar(), level: l.level, res: l.res}
+}
+
+// Sugared returns the Logger sharing the outputs and the level of the logger.
+func (l FastLogger) Sugared() Logger {
+	return Logger{sugared: l.logger.Sugar(), level: l.level, res: l.res}
+}
+
+// Desugar returns the underlying zap.Logger.
+func (l FastLogger) Desugar() *zap.Logger {
+	return l.logger
+}
+
+// With adds fields to the logging context.
+func (l FastLogger) With(fields ...Field) FastLogger {
+	l.logger = l.logger.With(fields...)
+	return l
+}
+
+// Named adds a sub-scope to the name of the logger.
+func (l FastLogger) Named(name string) FastLogger {
+	l.logger = l.logger.Named(name)
+	return l
+}
+
+// Enabled reports whether entries at the level would be written by any output.
+func (l FastLogger) Enabled(level Level) bool {
+	return l.logger.Core().Enabled(zapcore.Level(level))
+}
+
+// SetLevel changes the logging level at runtime.
+func (l FastLogger) SetLevel(level Level) {
+	l.Sugared().SetLevel(level)
+}
+
+// GetLevel returns the current logging level.
+func (l FastLogger) GetLevel() Level {
+	return Level(l.level.Level())
+}
+
+// Sync flushes any buffered log entries.
+func (l FastLogger) Sync() error {
+	return l.logger.Sync()
+}
+
+// Close flushes any buffered log entries and closes the log files held by the
+// logger.
+func (l FastLogger) Close() error {
+	return l.Sugared().Close()
+}
+
+// Debug logs a message at DebugLevel with the given fields.
+func (l FastLogger) Debug(msg string, fields ...Field) {
+	l.logger.Debug(msg, fields...)
+}
+
+// Info logs a message at InfoLevel with the given fields.
+func (l FastLogger) Info(msg string, fields ...Field) {
+	l.logger.Info(msg, fields...)
+}
+
+// Warn logs a message at WarnLevel with the given fields.
+func (l FastLogger) Warn(msg string, fields ...Field) {
+	l.logger.Warn(msg, fields...)
+}
+
+// Error logs a message at ErrorLevel with the given fields.
+func (l FastLogger) Error(msg string, fields ...Field) {
+	l.logger.Error(msg, fields...)
+}
+
+// DPanic logs a message at DPanicLevel with the given fields. In development,
+// the logger then panics.
+func (l FastLogger) DPanic(msg string, fields ...Field) {
+	l.logger.DPanic(msg, fields...)
+}
+
+// Panic logs a message at PanicLevel with the given fields, then panics.
+func (l FastLogger) Panic(msg string, fields ...Field) {
+	l.logger.Panic(msg, fields...)
+}
+
+// Fatal logs a message at FatalLevel with the given fields, then calls os.Exit.
+func (l FastLogger) Fatal(msg string, fields ...Field) {
+	l.logger.Fatal(msg, fields...)
+}
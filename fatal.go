@@ -0,0 +1,29 @@
+package logger
+
+import (
+	"os"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// fatalHook flushes the logger before the process exits on a fatal entry, so
+// that the fatal message itself is never lost in a buffer or a queue.
+type fatalHook struct {
+	core zapcore.Core
+	res  *resources
+	code int
+}
+
+func newFatalHook(core zapcore.Core, res *resources, code int) fatalHook {
+	if code == 0 {
+		code = 1
+	}
+	return fatalHook{core: core, res: res, code: code}
+}
+
+// OnWrite syncs the outputs, releases the resources and exits.
+func (h fatalHook) OnWrite(*zapcore.CheckedEntry, []zapcore.Field) {
+	h.core.Sync()
+	h.res.close()
+	os.Exit(h.code)
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFatalFlushes(t *testing.T) {
+	if filename := os.Getenv("LOGGER_FATAL_FILENAME"); filename != "" {
+		// the subprocess buffers the file for longer than it runs, so the
+		// message only reaches the file if the fatal hook flushes it.
+		l := New(Options{
+			Filename:      filename,
+			Buffer:        &BufferConfig{FlushInterval: time.Hour},
+			FatalExitCode: 3,
+		})
+		l.Info("before")
+		l.Fatal("fatal message")
+		return
+	}
+
+	filename := filepath.Join(t.TempDir(), "fatal.log")
+	cmd := exec.Command(os.Args[0], "-test.run=^TestFatalFlushes$")
+	cmd.Env = append(os.Environ(), "LOGGER_FATAL_FILENAME="+filename)
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("subprocess exited with %v, want exit status 3", err)
+	}
+	b, err := os.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), `"msg":"before"`) || !strings.Contains(string(b), `"msg":"fatal message"`) {
+		t.Fatalf("the log file lacks the messages:\n%s", b)
+	}
+}
@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Field is a strongly-typed key/value pair which avoids the reflection and
+// allocations of the sugared API.
+type Field = zapcore.Field
+
+// String constructs a field with the given key and string value.
+func String(key, val string) Field {
+	return zap.String(key, val)
+}
+
+// Stringer constructs a field with the given key and the output of the value's
+// String method, which is only called when the field is encoded.
+func Stringer(key string, val fmt.Stringer) Field {
+	return zap.Stringer(key, val)
+}
+
+// Bool constructs a field with the given key and bool value.
+func Bool(key string, val bool) Field {
+	return zap.Bool(key, val)
+}
+
+// Int constructs a field with the given key and int value.
+func Int(key string, val int) Field {
+	return zap.Int(key, val)
+}
+
+// Int64 constructs a field with the given key and int64 value.
+func Int64(key string, val int64) Field {
+	return zap.Int64(key, val)
+}
+
+// Uint constructs a field with the given key and uint value.
+func Uint(key string, val uint) Field {
+	return zap.Uint(key, val)
+}
+
+// Uint64 constructs a field with the given key and uint64 value.
+func Uint64(key string, val uint64) Field {
+	return zap.Uint64(key, val)
+}
+
+// Float64 constructs a field with the given key and float64 value.
+func Float64(key string, val float64) Field {
+	return zap.Float64(key, val)
+}
+
+// Duration constructs a field with the given key and time.Duration value.
+func Duration(key string, val time.Duration) Field {
+	return zap.Duration(key, val)
+}
+
+// Time constructs a field with the given key and time.Time value.
+func Time(key string, val time.Time) Field {
+	return zap.Time(key, val)
+}
+
+// Err constructs a field which carries the error under the key "error". It is
+// named Err since Error logs a message.
+func Err(err error) Field {
+	return zap.Error(err)
+}
+
+// Any constructs a field with the given key and an arbitrary value, choosing
+// the best way to encode it. Structs, maps and slices are encoded as nested
+// JSON by reflection, and values implementing zapcore.ObjectMarshaler or
+// zapcore.ArrayMarshaler are encoded by their own methods, which is cheaper.
+// It works with WithFields and the *Fields methods, while the *w methods and
+// With treat the loosely-typed pairs the same way:
+//
+//	logger.InfoFields("user created", logger.Any("user", user))
+//	logger.Infow("user created", "user", user)
+func Any(key string, val interface{}) Field {
+	return zap.Any(key, val)
+}
+
+// Lazy constructs a field with the given key and a value computed by fn, which
+// only runs when the entry is written. It keeps expensive values from being
+// computed for disabled levels:
+//
+//	logger.DebugFields("cache state", logger.Lazy("entries", cache.Dump))
+//
+// The value is encoded as Any does. Note that fn runs once for every output
+// writing the entry.
+func Lazy(key string, fn func() interface{}) Field {
+	// the key of an inline field is not encoded, it is only set for the
+	// redaction by key.
+	return Field{Key: key, Type: zapcore.InlineMarshalerType, Interface: lazyValue{key: key, fn: fn}}
+}
+
+// lazyValue adds the field computed by fn to the encoder it is inlined in.
+type lazyValue struct {
+	key string
+	fn  func() interface{}
+}
+
+// MarshalLogObject computes the value and adds it.
+func (v lazyValue) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	zap.Any(v.key, v.fn()).AddTo(enc)
+	return nil
+}
+
+// Object constructs a field with the given key and a value which encodes
+// itself by implementing zapcore.ObjectMarshaler.
+func Object(key string, val zapcore.ObjectMarshaler) Field {
+	return zap.Object(key, val)
+}
+
+// WithFields adds strongly-typed fields to the logging context, which skips
+// the handling of loosely-typed pairs done by With.
+func (l Logger) WithFields(fields ...Field) Logger {
+	l.sugared = l.sugared.Desugar().With(fields...).Sugar()
+	return l
+}
+
+// Fields is a set of fields keyed by name, like logrus.Fields.
+type Fields map[string]interface{}
+
+// WithField adds a field to the logging context, like WithField of logrus.
+func (l Logger) WithField(key string, value interface{}) Logger {
+	return l.WithFields(zap.Any(key, value))
+}
+
+// WithFieldMap adds the fields to the logging context sorted by key, like
+// WithFields of logrus, which is named so since WithFields takes strongly-typed
+// fields here. Replacing the logrus calls takes a rename:
+//
+//	logger.WithFieldMap(logger.Fields{"user": id, "retries": n}).Info("login")
+func (l Logger) WithFieldMap(fields Fields) Logger {
+	keys := make([]string, 0, len(fields))
+	for key := range fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	zapFields := make([]Field, 0, len(keys))
+	for _, key := range keys {
+		zapFields = append(zapFields, zap.Any(key, fields[key]))
+	}
+	return l.WithFields(zapFields...)
+}
+
+// WithError adds err to the logging context as the "error" field, along with
+// its verbose form if err implements fmt.Formatter. A nil err leaves the
+// logger unchanged.
+func (l Logger) WithError(err error) Logger {
+	if err == nil {
+		return l
+	}
+	return l.WithFields(Err(err))
+}
+
+// WithGroup nests the fields added to the logger afterwards, and the ones of
+// every entry, under name, like the groups of slog. The JSON output nests them
+// in an object, while logfmt prefixes their keys with name and a dot:
+//
+//	logger.WithGroup("http").With("method", "GET").Info("request")
+//	// {"msg":"request","http":{"method":"GET"}}
+//
+// The groups can be nested further. An empty name leaves the logger unchanged.
+// Note that the seq field of WithSequence is nested as well.
+//
+// A name already used as the key of a field of the enclosing group, or of a
+// built-in field such as msg at the top level, is suffixed with "_group" so
+// that the JSON objects hold no duplicate keys:
+//
+//	logger.With("http", "on").WithGroup("http").With("method", "GET").Info("request")
+//	// {"msg":"request","http":"on","http_group":{"method":"GET"}}
+func (l Logger) WithGroup(name string) Logger {
+	if name == "" {
+		return l
+	}
+	return l.WithFields(zap.Namespace(name))
+}
+
+// DebugFields logs a message with the given fields.
+func (l Logger) DebugFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Debug(msg, fields...)
+}
+
+// InfoFields logs a message with the given fields.
+func (l Logger) InfoFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Info(msg, fields...)
+}
+
+// WarnFields logs a message with the given fields.
+func (l Logger) WarnFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Warn(msg, fields...)
+}
+
+// ErrorFields logs a message with the given fields.
+func (l Logger) ErrorFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Error(msg, fields...)
+}
+
+// DPanicFields logs a message with the given fields. In development, the
+// logger then panics.
+func (l Logger) DPanicFields(msg string, fields ...Field) {
+	l.sugared.Desugar().DPanic(msg, fields...)
+}
+
+// PanicFields logs a message with the given fields, then panics.
+func (l Logger) PanicFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Panic(msg, fields...)
+}
+
+// FatalFields logs a message with the given fields, then calls os.Exit.
+func (l Logger) FatalFields(msg string, fields ...Field) {
+	l.sugared.Desugar().Fatal(msg, fields...)
+}
+
+// WithFields adds strongly-typed fields to the logging context of the standard
+// logger.
+func WithFields(fields ...Field) Logger {
+	return StandardLogger().WithFields(fields...)
+}
+
+// WithField adds a field to the logging context of the standard logger.
+func WithField(key string, value interface{}) Logger {
+	return StandardLogger().WithField(key, value)
+}
+
+// WithFieldMap adds the fields to the logging context of the standard logger
+// sorted by key.
+func WithFieldMap(fields Fields) Logger {
+	return StandardLogger().WithFieldMap(fields)
+}
+
+// WithError adds err to the logging context of the standard logger as the
+// "error" field.
+func WithError(err error) Logger {
+	return StandardLogger().WithError(err)
+}
+
+// WithGroup nests the fields added to a child of the standard logger
+// afterwards under name.
+func WithGroup(name string) Logger {
+	return StandardLogger().WithGroup(name)
+}
+
+// DebugFields logs a message with the given fields.
+func DebugFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Debug(msg, fields...)
+}
+
+// InfoFields logs a message with the given fields.
+func InfoFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Info(msg, fields...)
+}
+
+// WarnFields logs a message with the given fields.
+func WarnFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Warn(msg, fields...)
+}
+
+// ErrorFields logs a message with the given fields.
+func ErrorFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Error(msg, fields...)
+}
+
+// DPanicFields logs a message with the given fields. In development, the
+// logger then panics.
+func DPanicFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().DPanic(msg, fields...)
+}
+
+// PanicFields logs a message with the given fields, then panics.
+func PanicFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Panic(msg, fields...)
+}
+
+// FatalFields logs a message with the given fields, then calls os.Exit.
+func FatalFields(msg string, fields ...Field) {
+	StandardLogger().sugared.Desugar().Fatal(msg, fields...)
+}
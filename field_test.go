@@ -0,0 +1,95 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLazy(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, Level: InfoLevel})
+
+	var calls int
+	field := Lazy("expensive", func() interface{} {
+		calls++
+		return "computed"
+	})
+	l.DebugFields("disabled", field)
+	if calls != 0 {
+		t.Fatalf("the function is called %d times for a disabled level, want 0", calls)
+	}
+
+	l.InfoFields("enabled", field)
+	if calls != 1 {
+		t.Errorf("the function is called %d times, want 1", calls)
+	}
+	if line := decodeLines(t, &buf)[0]; line["expensive"] != "computed" {
+		t.Errorf("expensive = %v, want computed", line["expensive"])
+	}
+}
+
+func TestWithGroup(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf})
+	l.WithGroup("a").With("x", 1).WithGroup("b").With("y", 2).Info("nested")
+
+	line := decodeLines(t, &buf)[0]
+	want := map[string]interface{}{"x": 1.0, "b": map[string]interface{}{"y": 2.0}}
+	if !reflect.DeepEqual(line["a"], want) {
+		t.Errorf("a = %v, want %v", line["a"], want)
+	}
+
+	// decodeLines keeps the last of duplicate keys, so the raw lines are
+	// checked as well.
+	for _, tt := range []struct {
+		logger Logger
+		key    string
+	}{
+		{l.With("http", "flat").WithGroup("http").With("k", 1), "http_group"},
+		{l.WithFields(String("http", "flat")).WithGroup("http").WithGroup("http").With("k", 1), "http_group"},
+		{l.With("http", "flat", "http_group", "flat").WithGroup("http").With("k", 1), "http_group_group"},
+		{l.WithGroup("msg").With("k", 1), "msg_group"},
+	} {
+		buf.Reset()
+		tt.logger.Info("collision")
+		if n := strings.Count(buf.String(), `"`+tt.key+`":{`); n != 1 {
+			t.Errorf("got %d %s groups, want 1: %s", n, tt.key, buf.String())
+		}
+		line := decodeLines(t, &buf)[0]
+		if _, ok := line[tt.key].(map[string]interface{}); !ok {
+			t.Errorf("%s = %v, want the group", tt.key, line[tt.key])
+		}
+	}
+
+	// a group is not renamed for the keys of another level.
+	buf.Reset()
+	l.With("k", 1).WithGroup("a").With("a", 2).WithGroup("k").With("z", 3).Info("levels")
+	line = decodeLines(t, &buf)[0]
+	want = map[string]interface{}{"a": 2.0, "k": map[string]interface{}{"z": 3.0}}
+	if !reflect.DeepEqual(line["a"], want) {
+		t.Errorf("a = %v, want %v", line["a"], want)
+	}
+}
+
+// BenchmarkWith passes the typed fields to With, which checks every argument
+// for a field before treating it as a key.
+func BenchmarkWith(b *testing.B) {
+	l := New(Options{Writer: io.Discard})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.With(String("user", "alice"), Int("attempt", 3), Bool("admin", false))
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := New(Options{Writer: io.Discard})
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l.WithFields(String("user", "alice"), Int("attempt", 3), Bool("admin", false))
+	}
+}
@@ -4,6 +4,8 @@ go 1.16
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
-	go.uber.org/zap v1.17.0
+	go.opentelemetry.io/otel/trace v1.7.0
+	go.uber.org/zap v1.22.0
 	gopkg.in/natefinch/lumberjack.v2 v2.0.0
+	gopkg.in/yaml.v2 v2.2.8 // indirect
 )
@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"go.uber.org/zap/zapcore"
+)
+
+// groupSuffix is appended to the name of a group which is already the key of
+// a field, until the name is free.
+const groupSuffix = "_group"
+
+// groupKeys holds the keys of the fields added to a namespace, the ones
+// added by every With call are chained to the ones added before.
+type groupKeys struct {
+	parent *groupKeys
+	keys   []string
+}
+
+func (k *groupKeys) has(key string) bool {
+	for ; k != nil; k = k.parent {
+		for _, used := range k.keys {
+			if used == key {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// groupCore renames the namespaces added by With, which are the groups of
+// WithGroup and slog, whose names are already the keys of fields in the
+// enclosing namespace, so that the JSON objects hold no duplicate keys.
+type groupCore struct {
+	zapcore.Core
+	keys *groupKeys
+}
+
+// newGroupCore wraps core, the keys of the built-in fields of the encoder
+// config are reserved at the top level.
+func newGroupCore(core zapcore.Core, cfg zapcore.EncoderConfig) *groupCore {
+	var keys []string
+	for _, key := range []string{
+		cfg.MessageKey, cfg.LevelKey, cfg.TimeKey, cfg.NameKey,
+		cfg.CallerKey, cfg.FunctionKey, cfg.StacktraceKey,
+	} {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return &groupCore{Core: core, keys: &groupKeys{keys: keys}}
+}
+
+// With adds fields to a copy of the core, renaming the colliding namespaces.
+// The fields are copied before being renamed since zap owns them.
+func (c *groupCore) With(fields []zapcore.Field) zapcore.Core {
+	keys, added := c.keys, []string(nil)
+	renamed := fields
+	for i, f := range fields {
+		if f.Type != zapcore.NamespaceType {
+			if f.Key == "" {
+				continue
+			}
+			if added == nil {
+				added = make([]string, 0, len(fields)-i)
+			}
+			added = append(added, f.Key)
+			continue
+		}
+		used := &groupKeys{parent: keys, keys: added}
+		name := f.Key
+		for used.has(name) {
+			name += groupSuffix
+		}
+		if name != f.Key {
+			if &renamed[0] == &fields[0] {
+				renamed = append([]zapcore.Field(nil), fields...)
+			}
+			renamed[i].Key = name
+		}
+		// the fields added afterwards are in the new namespace.
+		keys, added = nil, nil
+	}
+	if len(added) > 0 {
+		keys = &groupKeys{parent: keys, keys: added}
+	}
+	return &groupCore{Core: c.Core.With(renamed), keys: keys}
+}
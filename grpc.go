@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// GRPCLoggerV2 adapts Logger to grpclog.LoggerV2 of gRPC, so that the logs of
+// gRPC go through the logger:
+//
+//	grpclog.SetLoggerV2(l.GRPCLogger())
+//
+// It implements the interface without importing gRPC.
+type GRPCLoggerV2 struct {
+	sugared *zap.SugaredLogger
+}
+
+// the method set of grpclog.LoggerV2, which keeps the adapter in line with
+// the interface without importing gRPC.
+var _ interface {
+	Info(args ...interface{})
+	Infoln(args ...interface{})
+	Infof(format string, args ...interface{})
+	Warning(args ...interface{})
+	Warningln(args ...interface{})
+	Warningf(format string, args ...interface{})
+	Error(args ...interface{})
+	Errorln(args ...interface{})
+	Errorf(format string, args ...interface{})
+	Fatal(args ...interface{})
+	Fatalln(args ...interface{})
+	Fatalf(format string, args ...interface{})
+	V(l int) bool
+} = (*GRPCLoggerV2)(nil)
+
+// GRPCLogger returns the gRPC logger writing to the logger. The caller
+// annotation is suppressed since it always points into gRPC.
+func (l Logger) GRPCLogger() *GRPCLoggerV2 {
+	return &GRPCLoggerV2{sugared: l.sugared.Desugar().WithOptions(zap.WithCaller(false)).Sugar()}
+}
+
+// GRPCLogger returns the gRPC logger writing to the standard logger.
+func GRPCLogger() *GRPCLoggerV2 {
+	return StandardLogger().GRPCLogger()
+}
+
+// Info logs at InfoLevel, the arguments are handled in the manner of fmt.Print.
+func (g *GRPCLoggerV2) Info(args ...interface{}) {
+	g.sugared.Info(args...)
+}
+
+// Infoln logs at InfoLevel, the arguments are handled in the manner of fmt.Println.
+func (g *GRPCLoggerV2) Infoln(args ...interface{}) {
+	g.sugared.Info(sprintln(args))
+}
+
+// Infof logs at InfoLevel, the arguments are handled in the manner of fmt.Printf.
+func (g *GRPCLoggerV2) Infof(format string, args ...interface{}) {
+	g.sugared.Infof(format, args...)
+}
+
+// Warning logs at WarnLevel, the arguments are handled in the manner of fmt.Print.
+func (g *GRPCLoggerV2) Warning(args ...interface{}) {
+	g.sugared.Warn(args...)
+}
+
+// Warningln logs at WarnLevel, the arguments are handled in the manner of fmt.Println.
+func (g *GRPCLoggerV2) Warningln(args ...interface{}) {
+	g.sugared.Warn(sprintln(args))
+}
+
+// Warningf logs at WarnLevel, the arguments are handled in the manner of fmt.Printf.
+func (g *GRPCLoggerV2) Warningf(format string, args ...interface{}) {
+	g.sugared.Warnf(format, args...)
+}
+
+// Error logs at ErrorLevel, the arguments are handled in the manner of fmt.Print.
+func (g *GRPCLoggerV2) Error(args ...interface{}) {
+	g.sugared.Error(args...)
+}
+
+// Errorln logs at ErrorLevel, the arguments are handled in the manner of fmt.Println.
+func (g *GRPCLoggerV2) Errorln(args ...interface{}) {
+	g.sugared.Error(sprintln(args))
+}
+
+// Errorf logs at ErrorLevel, the arguments are handled in the manner of fmt.Printf.
+func (g *GRPCLoggerV2) Errorf(format string, args ...interface{}) {
+	g.sugared.Errorf(format, args...)
+}
+
+// Fatal logs at FatalLevel and exits, the arguments are handled in the manner
+// of fmt.Print.
+func (g *GRPCLoggerV2) Fatal(args ...interface{}) {
+	g.sugared.Fatal(args...)
+}
+
+// Fatalln logs at FatalLevel and exits, the arguments are handled in the
+// manner of fmt.Println.
+func (g *GRPCLoggerV2) Fatalln(args ...interface{}) {
+	g.sugared.Fatal(sprintln(args))
+}
+
+// Fatalf logs at FatalLevel and exits, the arguments are handled in the
+// manner of fmt.Printf.
+func (g *GRPCLoggerV2) Fatalf(format string, args ...interface{}) {
+	g.sugared.Fatalf(format, args...)
+}
+
+// V reports whether the logs at the gRPC verbosity level are enabled. The
+// level 0 is enabled along with InfoLevel, while the verbose levels above it
+// need DebugLevel.
+func (g *GRPCLoggerV2) V(level int) bool {
+	if level <= 0 {
+		return g.sugared.Desugar().Core().Enabled(zapcore.InfoLevel)
+	}
+	return g.sugared.Desugar().Core().Enabled(zapcore.DebugLevel)
+}
+
+// sprintln formats args like fmt.Sprintln without the trailing newline.
+func sprintln(args []interface{}) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
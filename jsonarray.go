@@ -0,0 +1,86 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"os"
+)
+
+// arrayEnd closes the JSON array of a log file, the elements are separated by
+// a comma and a newline and the last one is left without the comma.
+var arrayEnd = []byte("\n]\n")
+
+// arrayElements turns the newline-delimited entries of p into the elements of
+// a JSON array, either the first ones following the opening bracket or the
+// ones following the elements already written. The JSON encoder escapes the
+// newlines within an entry, so every line is one entry, which holds for the
+// writes batched by Buffer as well.
+func arrayElements(p []byte, first bool) []byte {
+	entries := bytes.TrimSuffix(p, []byte("\n"))
+	data := make([]byte, 0, len(p)+8)
+	if first {
+		data = append(data, "[\n"...)
+	} else {
+		data = append(data, ",\n"...)
+	}
+	return append(data, bytes.ReplaceAll(entries, []byte("\n"), []byte(",\n"))...)
+}
+
+// closeArray appends the closing bracket to the log file unless it is empty or
+// closed already. The file is written through its own descriptor, since the
+// write must not be rotated away by lumberjack.
+func (f *rotatingFile) closeArray() error {
+	file, err := os.OpenFile(f.Filename, os.O_RDWR|os.O_APPEND, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	closed, size, err := arrayClosed(file)
+	if err != nil || closed || size == 0 {
+		return err
+	}
+	_, err = file.Write(arrayEnd)
+	return err
+}
+
+// reopenArray removes the closing bracket from the log file if it has one, so
+// that the array is continued by the next write.
+func (f *rotatingFile) reopenArray() error {
+	file, err := os.OpenFile(f.Filename, os.O_RDWR, 0)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	closed, size, err := arrayClosed(file)
+	if err != nil || !closed {
+		return err
+	}
+	return file.Truncate(size - int64(len(arrayEnd)))
+}
+
+// arrayClosed reports whether file ends with the closing bracket, along with
+// the size of the file.
+func arrayClosed(file *os.File) (bool, int64, error) {
+	fi, err := file.Stat()
+	if err != nil {
+		return false, 0, err
+	}
+	size := fi.Size()
+	if size < int64(len(arrayEnd)) {
+		return false, size, nil
+	}
+
+	tail := make([]byte, len(arrayEnd))
+	if _, err := file.ReadAt(tail, size-int64(len(tail))); err != nil && err != io.EOF {
+		return false, size, err
+	}
+	return bytes.Equal(tail, arrayEnd), size, nil
+}
@@ -0,0 +1,141 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// ParseLevel parses a level name case insensitively, like "debug" or "INFO".
+// The names are the ones returned by String: debug, info, warn, error, dpanic,
+// panic, fatal and off. An empty string stands for InfoLevel, the default
+// level.
+func ParseLevel(s string) (Level, error) {
+	text := strings.ToLower(strings.TrimSpace(s))
+	if text == "off" {
+		return OffLevel, nil
+	}
+	var lvl zapcore.Level
+	if err := lvl.UnmarshalText([]byte(text)); err != nil {
+		return 0, fmt.Errorf("logger: unknown level %q", s)
+	}
+	return Level(lvl), nil
+}
+
+// String returns the lower-case name of the level.
+func (l Level) String() string {
+	if l == OffLevel {
+		return "off"
+	}
+	return zapcore.Level(l).String()
+}
+
+// CapitalString returns the upper-case name of the level.
+func (l Level) CapitalString() string {
+	if l == OffLevel {
+		return "OFF"
+	}
+	return zapcore.Level(l).CapitalString()
+}
+
+// Enabled reports whether the logs at target are enabled when l is the
+// minimum level.
+func (l Level) Enabled(target Level) bool {
+	return target >= l
+}
+
+// Zap returns the zapcore counterpart of the level.
+func (l Level) Zap() zapcore.Level {
+	return zapcore.Level(l)
+}
+
+// FromZap returns the level of the zapcore level.
+func FromZap(l zapcore.Level) Level {
+	return Level(l)
+}
+
+// MarshalText marshals the level to its name, so that it reads like "info" in
+// JSON or YAML rather than a number.
+func (l Level) MarshalText() ([]byte, error) {
+	if l < DebugLevel || l > OffLevel {
+		return nil, fmt.Errorf("logger: invalid level %d", l)
+	}
+	return []byte(l.String()), nil
+}
+
+// UnmarshalText unmarshals a level name as ParseLevel does.
+func (l *Level) UnmarshalText(text []byte) error {
+	lvl, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*l = lvl
+	return nil
+}
+
+// UnmarshalJSON unmarshals a level name, or a number as the level used to be
+// encoded before it had a name.
+func (l *Level) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var n int8
+	if err := json.Unmarshal(data, &n); err == nil {
+		*l = Level(n)
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("logger: invalid level %s", data)
+	}
+	return l.UnmarshalText([]byte(s))
+}
+
+// levelHandler serves the level of the logger over HTTP like zap.AtomicLevel,
+// while the changes go through SetLevel so that they unmute the logger. The
+// levels are named as by ParseLevel and String, so that "off" round-trips.
+type levelHandler struct {
+	logger Logger
+}
+
+type levelPayload struct {
+	Level *Level `json:"level"`
+}
+
+type levelError struct {
+	Error string `json:"error"`
+}
+
+func (h levelHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	enc := json.NewEncoder(w)
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelPayload
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelError{fmt.Sprintf("Request body must be well-formed JSON: %v", err)})
+			return
+		}
+		if req.Level == nil {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelError{"Must specify a logging level."})
+			return
+		}
+		if *req.Level < DebugLevel || *req.Level > OffLevel {
+			w.WriteHeader(http.StatusBadRequest)
+			enc.Encode(levelError{fmt.Sprintf("Invalid logging level %d.", *req.Level)})
+			return
+		}
+		h.logger.SetLevel(*req.Level)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		enc.Encode(levelError{"Only GET and PUT are supported."})
+		return
+	}
+	lvl := h.logger.GetLevel()
+	enc.Encode(levelPayload{Level: &lvl})
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func serveLevel(t *testing.T, h http.Handler, method, body string) (int, string) {
+	t.Helper()
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, "/loglevel", strings.NewReader(body)))
+	return w.Code, strings.TrimSpace(w.Body.String())
+}
+
+func TestLevelHandler(t *testing.T) {
+	l := New(Options{Writer: io.Discard})
+	h := l.LevelHandler()
+
+	if code, body := serveLevel(t, h, http.MethodGet, ""); code != http.StatusOK || body != `{"level":"info"}` {
+		t.Errorf("GET = %d %s, want 200 with info", code, body)
+	}
+	if code, body := serveLevel(t, h, http.MethodPut, `{"level":"debug"}`); code != http.StatusOK || body != `{"level":"debug"}` {
+		t.Errorf("PUT debug = %d %s, want 200 with debug", code, body)
+	}
+	if got := l.GetLevel(); got != DebugLevel {
+		t.Errorf("GetLevel() = %v, want debug", got)
+	}
+
+	if code, body := serveLevel(t, h, http.MethodPut, `{"level":"off"}`); code != http.StatusOK || body != `{"level":"off"}` {
+		t.Errorf("PUT off = %d %s, want 200 with off", code, body)
+	}
+	if code, body := serveLevel(t, h, http.MethodGet, ""); code != http.StatusOK || body != `{"level":"off"}` {
+		t.Errorf("GET = %d %s, want 200 with off", code, body)
+	}
+	if got := l.GetLevel(); got != OffLevel {
+		t.Errorf("GetLevel() = %v, want off", got)
+	}
+
+	for _, body := range []string{`{"level":"verbose"}`, `{"level":42}`, `{}`, `level=debug`} {
+		if code, _ := serveLevel(t, h, http.MethodPut, body); code != http.StatusBadRequest {
+			t.Errorf("PUT %s = %d, want 400", body, code)
+		}
+	}
+	if code, _ := serveLevel(t, h, http.MethodPost, `{"level":"warn"}`); code != http.StatusMethodNotAllowed {
+		t.Errorf("POST = %d, want 405", code)
+	}
+}
+
+func TestLevelHandlerMuted(t *testing.T) {
+	l := New(Options{Writer: io.Discard})
+	l.Mute()
+	serveLevel(t, l.LevelHandler(), http.MethodPut, `{"level":"warn"}`)
+	l.Unmute()
+
+	// the level set while muted takes over, like with SetLevel.
+	if got := l.GetLevel(); got != WarnLevel {
+		t.Errorf("GetLevel() = %v, want warn", got)
+	}
+}
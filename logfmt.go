@@ -0,0 +1,326 @@
+package logger
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtEncoder encodes entries as logfmt lines, like
+//
+//	ts=2006-01-02T15:04:05.000Z level=info msg="hello world" user=gopher
+//
+// The nested objects and arrays are encoded as JSON values, and the keys of
+// the fields in a namespace are prefixed by the name of the namespace and a
+// period.
+type logfmtEncoder struct {
+	cfg        zapcore.EncoderConfig
+	buf        *buffer.Buffer
+	namespaces []string
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return &logfmtEncoder{cfg: cfg, buf: bufferPool.Get()}
+}
+
+// Clone copies the encoder along with the fields added to it.
+func (e *logfmtEncoder) Clone() zapcore.Encoder {
+	clone := &logfmtEncoder{cfg: e.cfg, buf: bufferPool.Get()}
+	clone.buf.Write(e.buf.Bytes())
+	clone.namespaces = append([]string(nil), e.namespaces...)
+	return clone
+}
+
+// EncodeEntry encodes the built-in fields, the fields added to the encoder
+// and then the given fields.
+func (e *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	final := &logfmtEncoder{cfg: e.cfg, buf: bufferPool.Get()}
+
+	if e.cfg.TimeKey != "" {
+		final.AddTime(e.cfg.TimeKey, ent.Time)
+	}
+	if e.cfg.LevelKey != "" {
+		final.addKey(e.cfg.LevelKey)
+		if e.cfg.EncodeLevel != nil {
+			final.appendValue(encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeLevel(ent.Level, enc) }))
+		} else {
+			final.appendValue(ent.Level.String())
+		}
+	}
+	if e.cfg.NameKey != "" && ent.LoggerName != "" {
+		final.AddString(e.cfg.NameKey, ent.LoggerName)
+	}
+	if e.cfg.CallerKey != "" && ent.Caller.Defined {
+		final.addKey(e.cfg.CallerKey)
+		if e.cfg.EncodeCaller != nil {
+			final.appendValue(encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeCaller(ent.Caller, enc) }))
+		} else {
+			final.appendValue(ent.Caller.String())
+		}
+	}
+	if e.cfg.FunctionKey != "" && ent.Caller.Defined && ent.Caller.Function != "" {
+		final.AddString(e.cfg.FunctionKey, ent.Caller.Function)
+	}
+	if e.cfg.MessageKey != "" {
+		final.AddString(e.cfg.MessageKey, ent.Message)
+	}
+
+	if e.buf.Len() > 0 {
+		if final.buf.Len() > 0 {
+			final.buf.AppendByte(' ')
+		}
+		final.buf.Write(e.buf.Bytes())
+	}
+	final.namespaces = e.namespaces[:len(e.namespaces):len(e.namespaces)]
+	for _, f := range fields {
+		f.AddTo(final)
+	}
+	final.namespaces = nil
+
+	if e.cfg.StacktraceKey != "" && ent.Stack != "" {
+		final.AddString(e.cfg.StacktraceKey, ent.Stack)
+	}
+	if e.cfg.LineEnding != "" {
+		final.buf.AppendString(e.cfg.LineEnding)
+	} else {
+		final.buf.AppendString(zapcore.DefaultLineEnding)
+	}
+	return final.buf, nil
+}
+
+// addKey appends the key and the equals sign. The characters which would end
+// the key are replaced by underscores.
+func (e *logfmtEncoder) addKey(key string) {
+	if e.buf.Len() > 0 {
+		e.buf.AppendByte(' ')
+	}
+	for _, ns := range e.namespaces {
+		e.buf.AppendString(sanitizeLogfmtKey(ns))
+		e.buf.AppendByte('.')
+	}
+	e.buf.AppendString(sanitizeLogfmtKey(key))
+	e.buf.AppendByte('=')
+}
+
+// appendValue appends s, quoted if it can't stand alone.
+func (e *logfmtEncoder) appendValue(s string) {
+	if needsLogfmtQuote(s) {
+		e.buf.AppendString(strconv.Quote(s))
+	} else {
+		e.buf.AppendString(s)
+	}
+}
+
+func (e *logfmtEncoder) addJSON(key string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	e.addKey(key)
+	e.appendValue(string(b))
+	return nil
+}
+
+// AddArray adds the array as a JSON value.
+func (e *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+	return e.addJSON(key, m.Fields[key])
+}
+
+// AddObject adds the object as a JSON value.
+func (e *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+	return e.addJSON(key, m.Fields)
+}
+
+// AddReflected adds the value as a JSON value.
+func (e *logfmtEncoder) AddReflected(key string, v interface{}) error {
+	return e.addJSON(key, v)
+}
+
+// OpenNamespace prefixes the keys of the fields added afterwards.
+func (e *logfmtEncoder) OpenNamespace(key string) {
+	e.namespaces = append(e.namespaces, key)
+}
+
+// AddBinary adds the bytes encoded in base64.
+func (e *logfmtEncoder) AddBinary(key string, v []byte) {
+	e.AddString(key, base64.StdEncoding.EncodeToString(v))
+}
+
+// AddByteString adds the UTF-8 encoded bytes.
+func (e *logfmtEncoder) AddByteString(key string, v []byte) {
+	e.AddString(key, string(v))
+}
+
+// AddBool adds a bool.
+func (e *logfmtEncoder) AddBool(key string, v bool) {
+	e.addKey(key)
+	e.buf.AppendBool(v)
+}
+
+// AddComplex128 adds a complex number.
+func (e *logfmtEncoder) AddComplex128(key string, v complex128) {
+	e.addKey(key)
+	e.buf.AppendString(strconv.FormatComplex(v, 'f', -1, 128))
+}
+
+// AddComplex64 adds a complex number.
+func (e *logfmtEncoder) AddComplex64(key string, v complex64) {
+	e.addKey(key)
+	e.buf.AppendString(strconv.FormatComplex(complex128(v), 'f', -1, 64))
+}
+
+// AddDuration adds a duration encoded by the EncodeDuration of the config.
+func (e *logfmtEncoder) AddDuration(key string, v time.Duration) {
+	e.addKey(key)
+	if e.cfg.EncodeDuration == nil {
+		e.buf.AppendInt(int64(v))
+		return
+	}
+	e.appendValue(encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeDuration(v, enc) }))
+}
+
+// AddFloat64 adds a float.
+func (e *logfmtEncoder) AddFloat64(key string, v float64) {
+	e.addKey(key)
+	e.buf.AppendString(formatLogfmtFloat(v, 64))
+}
+
+// AddFloat32 adds a float.
+func (e *logfmtEncoder) AddFloat32(key string, v float32) {
+	e.addKey(key)
+	e.buf.AppendString(formatLogfmtFloat(float64(v), 32))
+}
+
+// AddInt adds an int.
+func (e *logfmtEncoder) AddInt(key string, v int) { e.AddInt64(key, int64(v)) }
+
+// AddInt64 adds an int.
+func (e *logfmtEncoder) AddInt64(key string, v int64) {
+	e.addKey(key)
+	e.buf.AppendInt(v)
+}
+
+// AddInt32 adds an int.
+func (e *logfmtEncoder) AddInt32(key string, v int32) { e.AddInt64(key, int64(v)) }
+
+// AddInt16 adds an int.
+func (e *logfmtEncoder) AddInt16(key string, v int16) { e.AddInt64(key, int64(v)) }
+
+// AddInt8 adds an int.
+func (e *logfmtEncoder) AddInt8(key string, v int8) { e.AddInt64(key, int64(v)) }
+
+// AddString adds a string, quoted if needed.
+func (e *logfmtEncoder) AddString(key, v string) {
+	e.addKey(key)
+	e.appendValue(v)
+}
+
+// AddTime adds a time encoded by the EncodeTime of the config.
+func (e *logfmtEncoder) AddTime(key string, v time.Time) {
+	e.addKey(key)
+	if e.cfg.EncodeTime == nil {
+		e.buf.AppendInt(v.UnixNano())
+		return
+	}
+	e.appendValue(encodePrimitive(func(enc zapcore.PrimitiveArrayEncoder) { e.cfg.EncodeTime(v, enc) }))
+}
+
+// AddUint adds an unsigned int.
+func (e *logfmtEncoder) AddUint(key string, v uint) { e.AddUint64(key, uint64(v)) }
+
+// AddUint64 adds an unsigned int.
+func (e *logfmtEncoder) AddUint64(key string, v uint64) {
+	e.addKey(key)
+	e.buf.AppendUint(v)
+}
+
+// AddUint32 adds an unsigned int.
+func (e *logfmtEncoder) AddUint32(key string, v uint32) { e.AddUint64(key, uint64(v)) }
+
+// AddUint16 adds an unsigned int.
+func (e *logfmtEncoder) AddUint16(key string, v uint16) { e.AddUint64(key, uint64(v)) }
+
+// AddUint8 adds an unsigned int.
+func (e *logfmtEncoder) AddUint8(key string, v uint8) { e.AddUint64(key, uint64(v)) }
+
+// AddUintptr adds a pointer.
+func (e *logfmtEncoder) AddUintptr(key string, v uintptr) { e.AddUint64(key, uint64(v)) }
+
+// encodePrimitive returns the value appended by fn, which is one of the
+// encoders of the config, as a string.
+func encodePrimitive(fn func(zapcore.PrimitiveArrayEncoder)) string {
+	m := zapcore.NewMapObjectEncoder()
+	m.AddArray("v", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		fn(enc)
+		return nil
+	}))
+	values, _ := m.Fields["v"].([]interface{})
+	var b strings.Builder
+	for _, v := range values {
+		switch v := v.(type) {
+		case float64:
+			b.WriteString(formatLogfmtFloat(v, 64))
+		case float32:
+			b.WriteString(formatLogfmtFloat(float64(v), 32))
+		default:
+			fmt.Fprint(&b, v)
+		}
+	}
+	return b.String()
+}
+
+func formatLogfmtFloat(v float64, bitSize int) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'f', -1, bitSize)
+}
+
+// needsLogfmtQuote reports whether s has to be quoted, which is when it is
+// empty or contains spaces, quotes, equals signs or unprintable characters.
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	for _, r := range s {
+		if r == ' ' || r == '=' || r == '"' || r == '\\' || r == utf8.RuneError || !unicode.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
+
+// sanitizeLogfmtKey replaces the characters not allowed in keys.
+func sanitizeLogfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || !unicode.IsPrint(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
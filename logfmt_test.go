@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func encodeLogfmt(t *testing.T, enc zapcore.Encoder, msg string, fields ...zapcore.Field) string {
+	t.Helper()
+	buf, err := enc.EncodeEntry(zapcore.Entry{Message: msg}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer buf.Free()
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+func TestLogfmtQuoting(t *testing.T) {
+	enc := newLogfmtEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	for _, tt := range []struct {
+		field zapcore.Field
+		want  string
+	}{
+		{zap.String("k", "plain"), `k=plain`},
+		{zap.String("k", ""), `k=""`},
+		{zap.String("k", "hello world"), `k="hello world"`},
+		{zap.String("k", "a=b"), `k="a=b"`},
+		{zap.String("k", `say "hi"`), `k="say \"hi\""`},
+		{zap.String("k", `C:\logs`), `k="C:\\logs"`},
+		{zap.String("k", "a\tb\n"), `k="a\tb\n"`},
+		{zap.String("k", "a\x00b"), `k="a\x00b"`},
+		{zap.String("k", "a\xffb"), `k="a\xffb"`},
+		{zap.String("k", "日本語"), `k=日本語`},
+		{zap.String("k", "a\u00a0b"), `k="a\u00a0b"`},
+		{zap.String("", "v"), `_=v`},
+		{zap.String("a key", "v"), `a_key=v`},
+		{zap.String("a=b", "v"), `a_b=v`},
+		{zap.String(`"k"`, "v"), `_k_=v`},
+		{zap.Strings("k", []string{"a b"}), `k="[\"a b\"]"`},
+	} {
+		want := "msg=m " + tt.want
+		if got := encodeLogfmt(t, enc, "m", tt.field); got != want {
+			t.Errorf("%s %q: got %s, want %s", tt.field.Key, tt.field.String, got, want)
+		}
+	}
+
+	if got, want := encodeLogfmt(t, enc, "hello world"), `msg="hello world"`; got != want {
+		t.Errorf("message: got %s, want %s", got, want)
+	}
+}
+
+func TestLogfmtNamespaces(t *testing.T) {
+	enc := newLogfmtEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	zap.Namespace("req").AddTo(enc)
+	zap.Int("id", 1).AddTo(enc)
+
+	got := encodeLogfmt(t, enc, "m", zap.Namespace("db"), zap.String("table", "users"))
+	if want := "msg=m req.id=1 req.db.table=users"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+	// the namespaces opened by the fields of an entry don't outlive it.
+	got = encodeLogfmt(t, enc, "m", zap.String("table", "users"))
+	if want := "msg=m req.id=1 req.table=users"; got != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
+
+func TestLogfmtNamespacesConcurrent(t *testing.T) {
+	enc := newLogfmtEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	// the slice of namespaces grows with room to spare, which the entries
+	// must not share.
+	for _, ns := range []string{"a", "b", "c"} {
+		enc.OpenNamespace(ns)
+	}
+
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			ns := fmt.Sprintf("g%d", g)
+			want := fmt.Sprintf("msg=m a.b.c.%s.i=", ns)
+			for i := 0; i < 100; i++ {
+				got := encodeLogfmt(t, enc, "m", zap.Namespace(ns), zap.Int("i", i))
+				if got != fmt.Sprintf("%s%d", want, i) {
+					t.Errorf("got %s, want %s%d", got, want, i)
+					return
+				}
+			}
+		}(g)
+	}
+	wg.Wait()
+}
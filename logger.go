@@ -1,13 +1,20 @@
 package logger
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-	"gopkg.in/natefinch/lumberjack.v2"
 )
 
 type Level int8
@@ -37,44 +44,625 @@ const (
 
 	// FatalLevel logs a message, then calls os.Exit(1).
 	FatalLevel
+
+	// OffLevel is above all the levels, setting it disables all the logs.
+	// Panic and Fatal still panic and exit without writing anything.
+	OffLevel
 )
 
 // Options is the option set for Logger.
 type Options struct {
-	// Stdout sets the writer as stdout if it is true.
-	Stdout bool
+	// Writer is the destination of logs, it takes precedence over Stdout and
+	// Filename if it is not nil. Writers implementing zapcore.WriteSyncer are
+	// synced as well.
+	Writer io.Writer `json:"-" yaml:"-"`
+
+	// Stdout sets the writer as stdout if it is true. The filesystem is not
+	// touched at all in this mode.
+	Stdout bool `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+
+	// Stderr sets the writer as stderr if it is true. If both Stdout and Stderr
+	// are true, entries at ErrorLevel or above go to stderr and the rest go to
+	// stdout.
+	Stderr bool `json:"stderr,omitempty" yaml:"stderr,omitempty"`
 
 	// ConsoleMode sets logger to be the console mode which claims the logger encoder type as console.
-	ConsoleMode bool
+	ConsoleMode bool `json:"console_mode,omitempty" yaml:"console_mode,omitempty"`
+
+	// Format is the encoding of the logs, like JSONFormat, ConsoleFormat or
+	// LogfmtFormat. It takes precedence over ConsoleMode if it is not empty.
+	Format Format `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Color colors the levels in console mode. Colors are only applied to
+	// stdout or stderr attached to a terminal, never to files or pipes.
+	Color bool `json:"color,omitempty" yaml:"color,omitempty"`
+
+	// PrettyJSON indents the JSON encoded entries over multiple lines for
+	// reading them by eye. It only applies to stdout and stderr, files always
+	// get one entry per line.
+	PrettyJSON bool `json:"pretty_json,omitempty" yaml:"pretty_json,omitempty"`
+
+	// SortKeys sorts the fields of the JSON encoded entries by key, nested
+	// objects included, which makes the output reproducible for comparing in
+	// tests. The built-in fields like the time and the message stay first in
+	// their usual order. It costs re-encoding every entry.
+	SortKeys bool `json:"sort_keys,omitempty" yaml:"sort_keys,omitempty"`
+
+	// JSONArray makes every JSON encoded log file a JSON array of the entries,
+	// one entry per line, rather than newline-delimited objects. The array is
+	// closed by Close and before the file is rotated, and continued if the
+	// file is opened again. A file left by a crash lacks the closing bracket,
+	// which is added once the file is closed or rotated by a later run. It
+	// doesn't apply to writers, stdout and stderr, or a custom Encoder.
+	JSONArray bool `json:"json_array,omitempty" yaml:"json_array,omitempty"`
+
+	// LevelEncoder determines how levels are encoded, it defaults to
+	// CapitalLevelEncoder. The color variants are applied to every output
+	// regardless of Color.
+	LevelEncoder LevelEncoder `json:"level_encoder,omitempty" yaml:"level_encoder,omitempty"`
+
+	// Encoder encodes the logs of all the outputs if it is not nil, taking
+	// precedence over ConsoleMode, Format and the other encoding options.
+	// It is cloned for every output. Syslog is not affected.
+	Encoder zapcore.Encoder `json:"-" yaml:"-"`
+
+	// DurationFormat determines how durations are encoded, it defaults to
+	// SecondsDurationFormat.
+	DurationFormat DurationFormat `json:"duration_format,omitempty" yaml:"duration_format,omitempty"`
+
+	// EncoderKeys overrides the keys of the built-in fields such as the
+	// timestamp and the message.
+	EncoderKeys EncoderKeys `json:"encoder_keys,omitempty" yaml:"encoder_keys,omitempty"`
+
+	// File is an open file to write logs to as it is, without rotation, for
+	// the files rotated by an external tool like logrotate. It takes
+	// precedence over Filename. ReopenFile reopens it after the tool moves it
+	// aside. The logger doesn't close it, while it closes the ones reopened.
+	File *os.File `json:"-" yaml:"-"`
 
 	// Filename is the file to write logs to.  Backup log files will be retained
-	// in the same directory.
-	Filename string
+	// in the same directory. It is ignored if Stdout or Stderr is true.
+	Filename string `json:"filename,omitempty" yaml:"filename,omitempty"`
 
 	// MaxSize is the maximum size in megabytes of the log file before it gets rotated.
-	MaxSize int
+	MaxSize int `json:"max_size,omitempty" yaml:"max_size,omitempty"`
 
 	// MaxAge is the maximum number of days to retain old log files based on the
 	// timestamp encoded in their filename.  Note that a day is defined as 24
 	// hours and may not exactly correspond to calendar days due to daylight
 	// savings, leap seconds, etc. The default is not to remove old log files
 	// based on age.
-	MaxAge int
+	MaxAge int `json:"max_age,omitempty" yaml:"max_age,omitempty"`
 
 	// MaxBackups is the maximum number of old log files to retain. The default
 	// is to retain all old log files (though MaxAge may still cause them to get
-	// deleted.)
-	MaxBackups int
+	// deleted.) Note that zero means retaining all of them rather than none,
+	// and negative values are rejected.
+	MaxBackups int `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+
+	// Compress determines if the rotated log files should be compressed using
+	// gzip. It only applies to the log file. The default is not to compress.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+
+	// Shards splits Filename into the given number of files if it is greater
+	// than 1, named by inserting the index before the extension, like
+	// app.0.log and app.1.log for app.log. The entries are written to the
+	// files in turn, which spreads the disk IO, and every file is rotated on
+	// its own. Note that the entries are no longer ordered across the files,
+	// merge them by time or with WithSequence to restore the order.
+	Shards int `json:"shards,omitempty" yaml:"shards,omitempty"`
+
+	// OnRotate is called with the path of the backup whenever a log file of
+	// the logger is rotated. It runs in a new goroutine after the backup is
+	// renamed and the new log file is opened, by then the backup may have
+	// been compressed to path+".gz" or removed if Compress, MaxBackups or
+	// MaxAge is set, since lumberjack processes backups concurrently.
+	OnRotate func(oldPath string) `json:"-" yaml:"-"`
+
+	// BackupNamePattern renames the backups of the log files from the
+	// lumberjack style "name-2006-01-02T15-04-05.000.ext" after the pattern,
+	// which supports the tokens below. The time is of the last write to the
+	// backup, so a daily backup is named after the day of its logs. A suffix
+	// like ".1" is appended if the name is taken. Since lumberjack can only
+	// find the backups named in its own style, the pattern can't be used with
+	// Compress, MaxBackups or MaxAge.
+	//
+	//	%n  the name of the log file without the extension, like "app"
+	//	%e  the extension of the log file, like ".log"
+	//	%Y  the year, like 2006
+	//	%m  the month, 01 to 12
+	//	%d  the day of the month, 01 to 31
+	//	%H  the hour, 00 to 23
+	//	%M  the minute, 00 to 59
+	//	%S  the second, 00 to 59
+	//	%%  a literal %
+	//
+	// For example, "%n.%Y%m%d%e" names the backups like "app.20060102.log".
+	BackupNamePattern string `json:"backup_name_pattern,omitempty" yaml:"backup_name_pattern,omitempty"`
+
+	// RotateDaily rotates the log files at midnight, so that every day has its
+	// own files. Midnight is in the local time zone unless UTC is true. The
+	// files are checked on writes, so a file idle across midnight is rotated
+	// by the next write, and so is a file left by a previous day's run.
+	RotateDaily bool `json:"rotate_daily,omitempty" yaml:"rotate_daily,omitempty"`
+
+	// RotateInterval rotates the log files at the multiples of the interval
+	// since the zero time, like every hour on the hour, in the same way as
+	// RotateDaily. Note that an interval of 24 hours rotates at midnight UTC.
+	RotateInterval time.Duration `json:"rotate_interval,omitempty" yaml:"rotate_interval,omitempty"`
+
+	// HandleSIGHUP rotates the log files and reopens File whenever the process
+	// receives SIGHUP, as the log management tools expect, or only syncs the
+	// outputs if there is neither of them. Every logger handles the signal on
+	// its own, and Close stops it. The failures are reported to
+	// InternalErrorWriter. It has no effect on js/wasm, which has no signals.
+	HandleSIGHUP bool `json:"handle_sighup,omitempty" yaml:"handle_sighup,omitempty"`
+
+	// FallbackStdout makes the logger write to stdout instead of failing when
+	// a log file can not be opened, like on a read-only file system. A single
+	// warning lists the files. The outputs falling back share a stdout output
+	// writing the logs any of them would have written, which is dropped if
+	// the primary output writes to stdout already.
+	// The audit files never fall back, since their logs must be kept.
+	FallbackStdout bool `json:"fallback_stdout,omitempty" yaml:"fallback_stdout,omitempty"`
 
 	// Level is a logging priority. Higher levels are more important.
-	Level Level
+	Level Level `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// Skip is the number of callers skipped by caller annotation, it overrides
+	// CallerSkip if it is non-zero.
+	//
+	// Deprecated: use CallerSkip instead.
+	Skip int `json:"skip,omitempty" yaml:"skip,omitempty"`
+
+	// CallerSkip is the number of extra callers skipped by caller annotation on
+	// top of the frame of this package, it is useful for wrappers of the logger.
+	CallerSkip int `json:"caller_skip,omitempty" yaml:"caller_skip,omitempty"`
+
+	// FullCaller annotates logs with the full path of the calling file rather
+	// than the trimmed package/file.go form.
+	FullCaller bool `json:"full_caller,omitempty" yaml:"full_caller,omitempty"`
+
+	// WithFunction annotates logs with the fully qualified name of the calling
+	// function as the "func" field, next to the caller. It is ignored if
+	// DisableCaller is true.
+	WithFunction bool `json:"with_function,omitempty" yaml:"with_function,omitempty"`
+
+	// DisableCaller stops annotating logs with the calling function's file
+	// name and line number.
+	DisableCaller bool `json:"disable_caller,omitempty" yaml:"disable_caller,omitempty"`
+
+	// StacktraceLevel records a stacktrace for logs at the level or above, it
+	// is disabled if nil. The frames of this package are excluded.
+	StacktraceLevel *Level `json:"stacktrace_level,omitempty" yaml:"stacktrace_level,omitempty"`
+
+	// TimeFormat is the Go time layout used to encode the timestamp, it
+	// defaults to "2006-01-02 15:04:05.000". The special values
+	// EpochTimeFormat, EpochMillisTimeFormat and EpochNanosTimeFormat encode
+	// the timestamp as a number since the Unix epoch in floating-point
+	// seconds, integer milliseconds and integer nanoseconds respectively.
+	TimeFormat string `json:"time_format,omitempty" yaml:"time_format,omitempty"`
+
+	// DisableTime omits the timestamp of logs, for collectors which stamp
+	// them on ingestion.
+	DisableTime bool `json:"disable_time,omitempty" yaml:"disable_time,omitempty"`
+
+	// UTC uses UTC instead of the local time for both the timestamps of logs
+	// and the names of the rotated log files.
+	UTC bool `json:"utc,omitempty" yaml:"utc,omitempty"`
+
+	// ErrorFilename is the file to write logs at ErrorLevel or above to, in
+	// addition to the outputs. It shares the rotation settings of Filename.
+	ErrorFilename string `json:"error_filename,omitempty" yaml:"error_filename,omitempty"`
+
+	// ErrorLevel is the minimum level of logs written to ErrorFilename. It
+	// defaults to ErrorLevel, since InfoLevel is the zero value it can not be
+	// chosen here.
+	ErrorLevel Level `json:"error_level,omitempty" yaml:"error_level,omitempty"`
+
+	// AuditFilename is the file to write all the logs to in JSON, in addition
+	// to the outputs. It is never rotated and is synced to disk after every
+	// log at ErrorLevel or above, see OutputConfig.Audit.
+	AuditFilename string `json:"audit_filename,omitempty" yaml:"audit_filename,omitempty"`
+
+	// Syslog sends logs to a remote syslog server as well if it is not nil.
+	// Close stops sending.
+	Syslog *SyslogConfig `json:"syslog,omitempty" yaml:"syslog,omitempty"`
+
+	// LevelFiles routes the logs at exactly the given levels to the files, in
+	// addition to the other outputs. So a log shows up in both Filename and
+	// the file of its level, and logs at levels missing from the map only
+	// show up in the other outputs. The files share the rotation settings of
+	// Filename and must be distinct.
+	LevelFiles map[Level]string `json:"level_files,omitempty" yaml:"level_files,omitempty"`
+
+	// Outputs are the additional outputs which logs are written to besides the
+	// one described above. Each of them carries its own encoder and level.
+	Outputs []OutputConfig `json:"outputs,omitempty" yaml:"outputs,omitempty"`
+
+	// ServiceName attaches the name of the service to every log as the
+	// "service" field if it is not empty.
+	ServiceName string `json:"service_name,omitempty" yaml:"service_name,omitempty"`
+
+	// WithHostname attaches the hostname to every log as the "host" field.
+	WithHostname bool `json:"with_hostname,omitempty" yaml:"with_hostname,omitempty"`
+
+	// WithPID attaches the process id to every log as the "pid" field.
+	WithPID bool `json:"with_pid,omitempty" yaml:"with_pid,omitempty"`
+
+	// BuildInfo is attached to every log as the "version", "commit",
+	// "commit_time" and "build_time" fields, the empty ones are left out. If
+	// it is zero, the version, commit and commit time are read from the info
+	// embedded in the binary by the go command, so the binaries built from a
+	// tagged module or a git checkout are annotated without setting it. The
+	// commit is only embedded since Go 1.18.
+	BuildInfo BuildInfo `json:"build_info,omitempty" yaml:"build_info,omitempty"`
+
+	// Fields are attached to every log, after the fields above and before the
+	// ones added by With. They are sorted by key.
+	Fields map[string]interface{} `json:"fields,omitempty" yaml:"fields,omitempty"`
+
+	// WithSequence attaches a counter increased by one for every log as the
+	// "seq" field, which tells if any log is lost on the way. The loggers
+	// derived by With and Named share the counter of their root logger, while
+	// every logger built by New starts over from 1. The entries dropped by
+	// Sampling, RateLimit or DedupWindow don't consume a number, but an entry
+	// does even if some outputs skip it by level, so the outputs with a higher
+	// level see gaps.
+	WithSequence bool `json:"with_sequence,omitempty" yaml:"with_sequence,omitempty"`
+
+	// RedactKeys are the keys of the fields whose values are replaced with
+	// "***", the keys are matched case insensitively. Only the top-level fields
+	// are redacted, not the ones nested in objects.
+	RedactKeys []string `json:"redact_keys,omitempty" yaml:"redact_keys,omitempty"`
+
+	// MaxFieldBytes truncates the message and the values of the string and
+	// bytes fields to the size in bytes if it is positive, with the
+	// "...(truncated)" suffix appended, which keeps a huge payload from
+	// blowing up the log files. A rune is never split. Like RedactKeys, it
+	// only applies to the top-level fields.
+	MaxFieldBytes int `json:"max_field_bytes,omitempty" yaml:"max_field_bytes,omitempty"`
+
+	// Sampling caps the volume of repeated logs if it is not nil.
+	Sampling *SamplingConfig `json:"sampling,omitempty" yaml:"sampling,omitempty"`
+
+	// RateLimit throttles each message to a rate if it is not nil, a summary
+	// of the suppressed logs is logged periodically, through the logger which
+	// suppressed the last of them so that it carries the fields of that
+	// logger. Close stops it.
+	RateLimit *RateLimitConfig `json:"rate_limit,omitempty" yaml:"rate_limit,omitempty"`
+
+	// DedupWindow collapses the logs repeating the level and message of a log
+	// written within the window if it is positive. Once the window closes, the
+	// message is logged again with the number of the dropped logs as the
+	// "repeated" field, through the logger which dropped the last of them so
+	// that it carries the fields of that logger. At most 1024 messages are
+	// tracked at once, the least recently logged one is summarized early to
+	// make room for a new one. Close stops it.
+	DedupWindow time.Duration `json:"dedup_window,omitempty" yaml:"dedup_window,omitempty"`
+
+	// SyncInterval syncs the outputs periodically if it is positive, which
+	// flushes Buffer and commits the log files to disk, so that few logs are
+	// lost if the process is killed without calling Sync. Close stops it.
+	SyncInterval time.Duration `json:"sync_interval,omitempty" yaml:"sync_interval,omitempty"`
+
+	// RingBufferSize retains the most recent logs in memory if it is positive,
+	// up to the size, which are returned by RecentLogs. They are encoded like
+	// the primary output and kept regardless of where it writes to, which
+	// suits the debug endpoints showing the recent logs.
+	RingBufferSize int `json:"ring_buffer_size,omitempty" yaml:"ring_buffer_size,omitempty"`
+
+	// Buffer buffers the writes to log files if it is not nil. Buffered logs
+	// are flushed by Sync and Close.
+	Buffer *BufferConfig `json:"buffer,omitempty" yaml:"buffer,omitempty"`
+
+	// Development puts the logger in development mode, in which DPanic logs
+	// panic rather than only being logged as in production. The primary
+	// output defaults to the console encoding unless Format is set, and
+	// stacktraces are recorded for logs at WarnLevel or above unless
+	// StacktraceLevel is set.
+	Development bool `json:"development,omitempty" yaml:"development,omitempty"`
+
+	// OnWrite is called with the level of every log written, which suits
+	// counting the logs by level for metrics. The logs filtered out by the
+	// levels, Sampling, RateLimit or DedupWindow are not counted, while the
+	// summaries of RateLimit and DedupWindow are. It is called on the logging
+	// goroutine, so it has to be cheap and must not block.
+	OnWrite func(level Level) `json:"-" yaml:"-"`
+
+	// InternalErrorWriter receives the errors of the logger itself, like the
+	// failures of writing to a full disk. It defaults to stderr, io.Discard
+	// silences them.
+	InternalErrorWriter io.Writer `json:"-" yaml:"-"`
+
+	// FatalExitCode is the exit code of the process after a fatal log. Zero
+	// stands for the default 1, a fatal log never exits successfully.
+	FatalExitCode int `json:"fatal_exit_code,omitempty" yaml:"fatal_exit_code,omitempty"`
+}
+
+// BufferConfig is the option set for buffering writes to log files.
+type BufferConfig struct {
+	// Size is the maximum amount of data in bytes buffered before flushing, it
+	// defaults to 256 kB.
+	Size int `json:"size,omitempty" yaml:"size,omitempty"`
+
+	// FlushInterval is how often the buffer is flushed, it defaults to 30
+	// seconds.
+	FlushInterval time.Duration `json:"flush_interval,omitempty" yaml:"flush_interval,omitempty"`
+}
+
+// SamplingConfig is the option set for sampling. Within each tick, the first
+// Initial entries with the same level and message are logged, after that only
+// every Thereafter-th entry is logged.
+type SamplingConfig struct {
+	// Initial is the number of entries logged as is per tick.
+	Initial int `json:"initial,omitempty" yaml:"initial,omitempty"`
+
+	// Thereafter is the sampling rate after Initial entries are logged, zero
+	// drops all of them.
+	Thereafter int `json:"thereafter,omitempty" yaml:"thereafter,omitempty"`
+
+	// Tick is the interval which the counters are reset at, it defaults to one
+	// second.
+	Tick time.Duration `json:"tick,omitempty" yaml:"tick,omitempty"`
+}
+
+// Validate checks the options for the mistakes which NewWithError would
+// report, without creating any file. It is handy for checking the options
+// loaded from a config file.
+func (opt Options) Validate() error {
+	levels := []Level{opt.Level}
+	if opt.StacktraceLevel != nil {
+		levels = append(levels, *opt.StacktraceLevel)
+	}
+	for _, lvl := range levels {
+		if lvl < DebugLevel || lvl > OffLevel {
+			return fmt.Errorf("logger: invalid level %d", lvl)
+		}
+	}
+
+	if opt.RotateInterval < 0 {
+		return fmt.Errorf("logger: invalid rotate interval %s", opt.RotateInterval)
+	}
+	if strings.ContainsAny(opt.BackupNamePattern, `/\`) {
+		return fmt.Errorf("logger: backup name pattern %q contains a path separator", opt.BackupNamePattern)
+	}
+	if opt.MaxFieldBytes < 0 {
+		return fmt.Errorf("logger: invalid max field bytes %d", opt.MaxFieldBytes)
+	}
+	if opt.SyncInterval < 0 {
+		return fmt.Errorf("logger: invalid sync interval %s", opt.SyncInterval)
+	}
+	if opt.RingBufferSize < 0 {
+		return fmt.Errorf("logger: invalid ring buffer size %d", opt.RingBufferSize)
+	}
+	if opt.DedupWindow < 0 {
+		return fmt.Errorf("logger: invalid dedup window %s", opt.DedupWindow)
+	}
+	if opt.RotateDaily && opt.RotateInterval != 0 {
+		return errors.New("logger: RotateDaily and RotateInterval can't be used together")
+	}
+	if opt.Syslog != nil {
+		if err := opt.Syslog.validate(); err != nil {
+			return err
+		}
+	}
+	if opt.RateLimit != nil {
+		if err := opt.RateLimit.validate(); err != nil {
+			return err
+		}
+	}
+
+	outputs := opt.outputs()
+	for _, output := range outputs {
+		if err := output.validate(); err != nil {
+			return err
+		}
+		if opt.BackupNamePattern != "" && output.toFile() && (output.Compress || output.MaxBackups != 0 || output.MaxAge != 0) {
+			return fmt.Errorf("logger: BackupNamePattern can't be used with Compress, MaxBackups or MaxAge of %s", output.Filename)
+		}
+	}
+
+	// two lumberjack loggers rotating the same file would clobber each other.
+	filenames := make(map[string]bool)
+	for _, output := range outputs {
+		if !output.toFile() {
+			continue
+		}
+		filename := filepath.Clean(output.Filename)
+		if filenames[filename] {
+			return fmt.Errorf("logger: %s is used by more than one output", output.Filename)
+		}
+		filenames[filename] = true
+	}
+
+	return nil
+}
+
+// clone returns a copy of opt which shares no slice, map or pointer with it,
+// except for the writers, the file, the encoder and the funcs.
+func (opt Options) clone() Options {
+	if opt.StacktraceLevel != nil {
+		lvl := *opt.StacktraceLevel
+		opt.StacktraceLevel = &lvl
+	}
+	if opt.Syslog != nil {
+		syslog := *opt.Syslog
+		opt.Syslog = &syslog
+	}
+	if opt.Sampling != nil {
+		sampling := *opt.Sampling
+		opt.Sampling = &sampling
+	}
+	if opt.RateLimit != nil {
+		rateLimit := *opt.RateLimit
+		opt.RateLimit = &rateLimit
+	}
+	if opt.Buffer != nil {
+		buffer := *opt.Buffer
+		opt.Buffer = &buffer
+	}
+	if opt.LevelFiles != nil {
+		levelFiles := make(map[Level]string, len(opt.LevelFiles))
+		for lvl, filename := range opt.LevelFiles {
+			levelFiles[lvl] = filename
+		}
+		opt.LevelFiles = levelFiles
+	}
+	if opt.Fields != nil {
+		fields := make(map[string]interface{}, len(opt.Fields))
+		for key, val := range opt.Fields {
+			fields[key] = val
+		}
+		opt.Fields = fields
+	}
+	opt.Outputs = append([]OutputConfig(nil), opt.Outputs...)
+	opt.RedactKeys = append([]string(nil), opt.RedactKeys...)
+	return opt
+}
+
+// development fills in the defaults of the development mode. It is applied to
+// a copy, so that Logger.Options returns the options as they were given.
+func (opt Options) development() Options {
+	if opt.Format == "" {
+		opt.ConsoleMode = true
+	}
+	if opt.StacktraceLevel == nil {
+		lvl := WarnLevel
+		opt.StacktraceLevel = &lvl
+	}
+	return opt
+}
+
+// nextRotation returns the function scheduling the rotations by time, or nil
+// if the files are only rotated by size.
+func (opt Options) nextRotation() func(time.Time) time.Time {
+	if opt.RotateDaily {
+		return dailyRotation(opt.UTC)
+	}
+	if opt.RotateInterval > 0 {
+		return intervalRotation(opt.RotateInterval)
+	}
+	return nil
+}
+
+// outputs returns the primary output configured by the top-level fields,
+// followed by Outputs, the error file, the audit file and the per-level files.
+func (opt Options) outputs() []OutputConfig {
+	outputs := append([]OutputConfig{{
+		Writer:      opt.Writer,
+		Stdout:      opt.Stdout,
+		Stderr:      opt.Stderr,
+		File:        opt.File,
+		ConsoleMode: opt.ConsoleMode,
+		Format:      opt.Format,
+		Filename:    opt.Filename,
+		MaxSize:     opt.MaxSize,
+		MaxAge:      opt.MaxAge,
+		MaxBackups:  opt.MaxBackups,
+		Compress:    opt.Compress,
+		Shards:      opt.Shards,
+		Level:       DebugLevel,
+	}}, opt.Outputs...)
+
+	if opt.ErrorFilename != "" {
+		errorLevel := opt.ErrorLevel
+		if errorLevel == InfoLevel {
+			errorLevel = ErrorLevel
+		}
+		outputs = append(outputs, OutputConfig{
+			ConsoleMode: opt.ConsoleMode,
+			Format:      opt.Format,
+			Filename:    opt.ErrorFilename,
+			MaxSize:     opt.MaxSize,
+			MaxAge:      opt.MaxAge,
+			MaxBackups:  opt.MaxBackups,
+			Compress:    opt.Compress,
+			Level:       errorLevel,
+		})
+	}
+
+	if opt.AuditFilename != "" {
+		outputs = append(outputs, OutputConfig{
+			Format:   JSONFormat,
+			Filename: opt.AuditFilename,
+			Level:    DebugLevel,
+			Audit:    true,
+		})
+	}
+
+	levels := make([]Level, 0, len(opt.LevelFiles))
+	for lvl := range opt.LevelFiles {
+		levels = append(levels, lvl)
+	}
+	sort.Slice(levels, func(i, j int) bool { return levels[i] < levels[j] })
+	for _, lvl := range levels {
+		outputs = append(outputs, OutputConfig{
+			ConsoleMode: opt.ConsoleMode,
+			Format:      opt.Format,
+			Filename:    opt.LevelFiles[lvl],
+			MaxSize:     opt.MaxSize,
+			MaxAge:      opt.MaxAge,
+			MaxBackups:  opt.MaxBackups,
+			Compress:    opt.Compress,
+			Level:       lvl,
+			ExactLevel:  true,
+		})
+	}
 
-	// Skip is the number of callers skipped by caller annotation
-	Skip int
+	return outputs
 }
 
 type Logger struct {
 	sugared *zap.SugaredLogger
+	level   zap.AtomicLevel
+	res     *resources
+}
+
+// resources are held by a root logger and shared by the loggers derived from
+// it.
+type resources struct {
+	closers []io.Closer
+	files   []*rotatingFile
+	opened  []*reopenableFile
+	output  *outputSwitch
+	ring    *ringBuffer
+	opt     Options
+
+	// parent is the resources of the logger cloned, whose log files are
+	// shared.
+	parent *resources
+
+	// mu guards the level saved by Mute.
+	mu      sync.Mutex
+	muted   bool
+	unmuted Level
+}
+
+// close releases the resources in reverse order, so that the ones built on
+// top of the files go first.
+func (r *resources) close() error {
+	var err error
+	for i := len(r.closers) - 1; i >= 0; i-- {
+		if cerr := r.closers[i].Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
+
+// sharedFile returns the log file named filename opened by the parents, or nil
+// if there is none.
+func (r *resources) sharedFile(filename string) *rotatingFile {
+	filename = filepath.Clean(filename)
+	for p := r.parent; p != nil; p = p.parent {
+		for _, file := range p.files {
+			if filepath.Clean(file.Filename) == filename {
+				return file
+			}
+		}
+	}
+	return nil
 }
 
 // With adds a variadic number of fields to the logging context. It accepts a
@@ -82,7 +670,125 @@ type Logger struct {
 // processing pairs, the first element of the pair is used as the field key
 // and the second as the field value.
 func (l Logger) With(args ...interface{}) Logger {
-	return Logger{sugared: l.sugared.With(args...)}
+	l.sugared = l.sugared.With(args...)
+	return l
+}
+
+// Named adds a sub-scope to the name of the logger, names are joined by
+// periods, so that l.Named("db").Named("pool") is named "db.pool".
+func (l Logger) Named(name string) Logger {
+	l.sugared = l.sugared.Named(name)
+	return l
+}
+
+// WithOptions clones the logger with the given zap options applied, such as
+// zap.AddStacktrace or zap.AddCallerSkip.
+func (l Logger) WithOptions(opts ...zap.Option) Logger {
+	l.sugared = l.sugared.Desugar().WithOptions(opts...).Sugar()
+	return l
+}
+
+// Desugar returns the underlying zap.Logger, which is faster but less
+// convenient than the sugared one.
+func (l Logger) Desugar() *zap.Logger {
+	return l.sugared.Desugar()
+}
+
+// Sugar returns the underlying zap.SugaredLogger.
+func (l Logger) Sugar() *zap.SugaredLogger {
+	return l.sugared
+}
+
+// SetLevel changes the logging level at runtime. Loggers derived from the same
+// root logger share the level, so all of them are affected.
+func (l Logger) SetLevel(level Level) {
+	l.res.mu.Lock()
+	defer l.res.mu.Unlock()
+
+	// the new level takes over a muted logger.
+	l.res.muted = false
+	l.level.SetLevel(zapcore.Level(level))
+}
+
+// GetLevel returns the current logging level.
+func (l Logger) GetLevel() Level {
+	return Level(l.level.Level())
+}
+
+// Options returns a copy of the options the logger was built with, except for
+// Level which is the current one, ignoring Mute. The loggers built by NewTest
+// and Nop return zero options. Modifying the copy doesn't affect the logger,
+// while the copy can be passed to New to build a sibling, see Clone.
+func (l Logger) Options() Options {
+	opt := l.res.opt.clone()
+	l.res.mu.Lock()
+	opt.Level = l.GetLevel()
+	if l.res.muted {
+		opt.Level = l.res.unmuted
+	}
+	l.res.mu.Unlock()
+	return opt
+}
+
+// Clone builds a new logger from the options of l modified by modify, which
+// may be nil. It panics like New if the logger can not be built:
+//
+//	child := l.Clone(func(o *logger.Options) { o.Level = logger.DebugLevel })
+//
+// The clone shares the log files of l with the same names rather than opening
+// them once more, so the files are rotated by l as configured for l, and they
+// are closed when l is closed. The clone has to be closed on its own for the
+// rest of its resources, it is not affected by SetLevel or SetOutput on l.
+func (l Logger) Clone(modify func(opt *Options)) Logger {
+	opt := l.Options()
+	if modify != nil {
+		modify(&opt)
+	}
+	clone, err := newLogger(opt, l.res)
+	if err != nil {
+		panic(err)
+	}
+	return clone
+}
+
+// Mute silences the logger until Unmute is called, by setting the level to
+// OffLevel. Panic and Fatal still panic and exit, without writing anything.
+// Loggers derived from the same root logger share the level, so all of them
+// are muted.
+func (l Logger) Mute() {
+	l.res.mu.Lock()
+	defer l.res.mu.Unlock()
+
+	if !l.res.muted {
+		l.res.muted, l.res.unmuted = true, l.GetLevel()
+	}
+	l.level.SetLevel(zapcore.Level(OffLevel))
+}
+
+// Unmute restores the level the logger had before Mute.
+func (l Logger) Unmute() {
+	l.res.mu.Lock()
+	defer l.res.mu.Unlock()
+
+	if l.res.muted {
+		l.res.muted = false
+		l.level.SetLevel(zapcore.Level(l.res.unmuted))
+	}
+}
+
+// LevelHandler returns an HTTP handler which reports the level on GET and
+// changes it on PUT, like SetLevel does. The levels are named as by ParseLevel,
+// "off" included:
+//
+//	curl -X PUT -d '{"level":"debug"}' localhost:8080/loglevel
+func (l Logger) LevelHandler() http.Handler {
+	return levelHandler{l}
+}
+
+// Enabled reports whether entries at the level would be written by any
+// output, which allows skipping the construction of expensive fields.
+func (l Logger) Enabled(level Level) bool {
+	return l.sugared.Desugar().Core().Enabled(zapcore.Level(level))
 }
 
 // Println is the alias for Info
@@ -95,6 +801,77 @@ func (l Logger) Printf(template string, args ...interface{}) {
 	l.sugared.Infof(template, args...)
 }
 
+// Sync flushes any buffered log entries. Applications should take care to
+// call Sync before exiting.
+func (l Logger) Sync() error {
+	return l.sugared.Sync()
+}
+
+// Close flushes any buffered log entries and closes the log files held by the
+// logger. Loggers derived from the same root logger share the files, so Close
+// should be called once all of them are done with logging.
+func (l Logger) Close() error {
+	unregister(l.res)
+	err := l.sugared.Sync()
+	if cerr := l.res.close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// SetOutput redirects the primary output of the logger, the one configured by
+// the top-level fields of Options, to w. The level, the encoder and the fields
+// are left as they are, and so are the other outputs. A nil w restores the
+// original destination, the log files are kept open until Close in any case.
+// Loggers derived from the same root logger share the output.
+func (l Logger) SetOutput(w io.Writer) {
+	l.res.output.set(w)
+}
+
+// RecentLogs returns the most recent logs retained by RingBufferSize, the
+// oldest first, or nil if it is not set. Loggers derived from the same root
+// logger share the logs.
+func (l Logger) RecentLogs() []string {
+	if l.res.ring == nil {
+		return nil
+	}
+	return l.res.ring.recent()
+}
+
+// ReopenFile reopens the files given by File by their names, which points the
+// logger at the new files after an external tool moved the old ones aside,
+// typically on SIGHUP. The buffered log entries are flushed before that.
+func (l Logger) ReopenFile() error {
+	if len(l.res.opened) == 0 {
+		return errors.New("logger: no File to reopen")
+	}
+
+	err := l.sugared.Sync()
+	for _, f := range l.res.opened {
+		if rerr := f.reopen(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
+// Rotate closes the log files and moves them aside as backups right away,
+// the buffered log entries are flushed before that. It returns ErrNoLogFile
+// if the logger doesn't write to any log file.
+func (l Logger) Rotate() error {
+	if len(l.res.files) == 0 {
+		return ErrNoLogFile
+	}
+
+	err := l.sugared.Sync()
+	for _, f := range l.res.files {
+		if rerr := f.Rotate(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
+
 // Debug uses fmt.Sprint to construct and log a message.
 func (l Logger) Debug(args ...interface{}) {
 	l.sugared.Debug(args...)
@@ -115,6 +892,12 @@ func (l Logger) Error(args ...interface{}) {
 	l.sugared.Error(args...)
 }
 
+// DPanic uses fmt.Sprint to construct and log a message. In development, the
+// logger then panics, see Options.Development.
+func (l Logger) DPanic(args ...interface{}) {
+	l.sugared.DPanic(args...)
+}
+
 // Panic uses fmt.Sprint to construct and log a message, then panics.
 func (l Logger) Panic(args ...interface{}) {
 	l.sugared.Panic(args...)
@@ -145,6 +928,12 @@ func (l Logger) Errorf(template string, args ...interface{}) {
 	l.sugared.Errorf(template, args...)
 }
 
+// DPanicf uses fmt.Sprintf to log a templated message. In development, the
+// logger then panics.
+func (l Logger) DPanicf(template string, args ...interface{}) {
+	l.sugared.DPanicf(template, args...)
+}
+
 // Panicf uses fmt.Sprintf to log a templated message, then panics.
 func (l Logger) Panicf(template string, args ...interface{}) {
 	l.sugared.Panicf(template, args...)
@@ -155,129 +944,524 @@ func (l Logger) Fatalf(template string, args ...interface{}) {
 	l.sugared.Fatalf(template, args...)
 }
 
+// Debugw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Debugw(msg string, keysAndValues ...interface{}) {
+	l.sugared.Debugw(msg, keysAndValues...)
+}
+
+// Infow logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Infow(msg string, keysAndValues ...interface{}) {
+	l.sugared.Infow(msg, keysAndValues...)
+}
+
+// Warnw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Warnw(msg string, keysAndValues ...interface{}) {
+	l.sugared.Warnw(msg, keysAndValues...)
+}
+
+// Errorw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Errorw(msg string, keysAndValues ...interface{}) {
+	l.sugared.Errorw(msg, keysAndValues...)
+}
+
+// DPanicw logs a message with some additional context. In development, the
+// logger then panics. The variadic key-value pairs are treated as they are in
+// With.
+func (l Logger) DPanicw(msg string, keysAndValues ...interface{}) {
+	l.sugared.DPanicw(msg, keysAndValues...)
+}
+
+// Panicw logs a message with some additional context, then panics. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Panicw(msg string, keysAndValues ...interface{}) {
+	l.sugared.Panicw(msg, keysAndValues...)
+}
+
+// Fatalw logs a message with some additional context, then calls os.Exit. The variadic
+// key-value pairs are treated as they are in With.
+func (l Logger) Fatalw(msg string, keysAndValues ...interface{}) {
+	l.sugared.Fatalw(msg, keysAndValues...)
+}
+
 // New returns the logger instance with Production Config by default.
+// It panics if the log file can not be prepared, use NewWithError to handle
+// the error instead.
 func New(opt Options) Logger {
-	encoderConfig := zap.NewProductionEncoderConfig()
-	encoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
-		enc.AppendString(t.Local().Format("2006-01-02 15:04:05.000"))
+	l, err := NewWithError(opt)
+	if err != nil {
+		panic(err)
 	}
-	encoderConfig.EncodeLevel = zapcore.CapitalLevelEncoder
+	return l
+}
+
+// NewWithError returns the logger instance with Production Config by default.
+// Failures of creating the log directory or opening the log file are returned
+// rather than causing a panic.
+func NewWithError(opt Options) (Logger, error) {
+	return newLogger(opt, nil)
+}
 
-	encoder := zapcore.NewJSONEncoder(encoderConfig)
-	if opt.ConsoleMode {
-		encoder = zapcore.NewConsoleEncoder(encoderConfig)
+// newLogger builds a logger which shares the log files of parent if it is not
+// nil.
+func newLogger(opt Options, parent *resources) (Logger, error) {
+	if err := opt.Validate(); err != nil {
+		return Logger{}, err
 	}
 
-	if err := os.MkdirAll(filepath.Dir(opt.Filename), os.ModePerm); err != nil {
-		panic(err)
+	res := &resources{output: &outputSwitch{}, opt: opt, parent: parent}
+	if opt.Development {
+		opt = opt.development()
+	}
+
+	encoderConfig := newEncoderConfig(opt)
+	level := zap.NewAtomicLevelAt(zapcore.Level(opt.Level))
+	outputs := opt.outputs()
+	outputs[0].sw = res.output
+	if opt.RingBufferSize > 0 {
+		res.ring = newRingBuffer(opt.RingBufferSize)
+		outputs = append(outputs, OutputConfig{
+			Writer:      res.ring,
+			ConsoleMode: opt.ConsoleMode,
+			Format:      opt.Format,
+			Level:       DebugLevel,
+		})
 	}
+	cores := make([]zapcore.Core, 0, len(outputs))
+	var failed []OutputConfig
+	var failedNames []string
+	var failedErrs []error
+	for _, output := range outputs {
+		core, err := output.newCore(opt, encoderConfig, level, res)
+		// the outputs are validated, so only opening a file can fail.
+		if err != nil && opt.FallbackStdout && output.toFile() && !output.Audit {
+			failed = append(failed, output)
+			failedNames = append(failedNames, output.Filename)
+			failedErrs = append(failedErrs, err)
+			continue
+		}
+		if err != nil {
+			return Logger{}, err
+		}
+		cores = append(cores, core)
+	}
+	// a single stdout core replaces all the failed files, unless the primary
+	// output writes there already.
+	if len(failed) > 0 && !(opt.Writer == nil && opt.Stdout) {
+		core, err := stdoutFallback(failed).newCore(opt, encoderConfig, level, res)
+		if err != nil {
+			return Logger{}, err
+		}
+		cores = append(cores, core)
+	}
+
+	if opt.Syslog != nil {
+		core, stop := newSyslogCore(*opt.Syslog, zapcore.NewJSONEncoder(encoderConfig), level)
+		cores = append(cores, wrapCore(core, opt))
+		res.closers = append(res.closers, stop)
+	}
+
+	// the skip applies to stacktraces as well, so it is added even if the
+	// caller annotation is disabled.
+	skip := 1 + opt.CallerSkip
+	if opt.Skip != 0 {
+		skip = opt.Skip
+	}
+	zapOpts := []zap.Option{zap.WithCaller(!opt.DisableCaller), zap.AddCallerSkip(skip)}
+	if opt.StacktraceLevel != nil {
+		zapOpts = append(zapOpts, zap.AddStacktrace(zapcore.Level(*opt.StacktraceLevel)))
+	}
+	if opt.Development {
+		zapOpts = append(zapOpts, zap.Development())
+	}
+
+	errorOutput := stderr
+	if opt.InternalErrorWriter != nil {
+		errorOutput = zapcore.Lock(zapcore.AddSync(opt.InternalErrorWriter))
+	}
+	zapOpts = append(zapOpts, zap.ErrorOutput(errorOutput))
 
-	var w zapcore.WriteSyncer
-	if opt.Stdout {
-		w = zapcore.AddSync(os.Stdout)
-	} else {
-		w = zapcore.AddSync(&lumberjack.Logger{
-			Filename:   opt.Filename,
-			MaxSize:    opt.MaxSize,
-			MaxBackups: opt.MaxBackups,
-			MaxAge:     opt.MaxAge,
-			LocalTime:  true,
+	core := zapcore.NewTee(cores...)
+	if opt.WithSequence {
+		core = newSeqCore(core, errorOutput)
+	}
+	if opt.OnWrite != nil {
+		// the hooks only fire if the entry is accepted by the wrapped core.
+		// They are below the suppressing cores, which drop entries before
+		// they reach the hooks but write their summaries through them.
+		core = zapcore.RegisterHooks(core, func(ent zapcore.Entry) error {
+			opt.OnWrite(Level(ent.Level))
+			return nil
 		})
 	}
+	if opt.Sampling != nil {
+		tick := opt.Sampling.Tick
+		if tick <= 0 {
+			tick = time.Second
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, opt.Sampling.Initial, opt.Sampling.Thereafter)
+	}
+	if opt.RateLimit != nil {
+		var stop closerFunc
+		core, stop = newRateLimitCore(core, *opt.RateLimit)
+		res.closers = append(res.closers, stop)
+	}
+	if opt.DedupWindow > 0 {
+		var stop closerFunc
+		core, stop = newDedupCore(core, opt.DedupWindow)
+		res.closers = append(res.closers, stop)
+	}
 
-	core := zapcore.NewCore(encoder, w, zapcore.Level(opt.Level))
-	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(opt.Skip))
-	return Logger{sugared: logger.Sugar()}
+	var fields []zap.Field
+	if opt.ServiceName != "" {
+		fields = append(fields, zap.String("service", opt.ServiceName))
+	}
+	if opt.WithHostname {
+		fields = append(fields, zap.String("host", hostname()))
+	}
+	if opt.WithPID {
+		fields = append(fields, zap.Int("pid", os.Getpid()))
+	}
+	buildInfo := opt.BuildInfo
+	if buildInfo == (BuildInfo{}) {
+		buildInfo = readBuildInfo()
+	}
+	fields = append(fields, buildInfo.fields()...)
+	keys := make([]string, 0, len(opt.Fields))
+	for key := range opt.Fields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		fields = append(fields, zap.Any(key, opt.Fields[key]))
+	}
+
+	// the groups are renamed above the other cores, whose derived copies
+	// carry the renamed fields.
+	core = newGroupCore(core, encoderConfig)
+	zapOpts = append(zapOpts, zap.WithFatalHook(newFatalHook(core, res, opt.FatalExitCode)))
+	logger := zap.New(core, zapOpts...).With(fields...)
+	if opt.SyncInterval > 0 {
+		res.closers = append(res.closers, startSyncer(logger, opt.SyncInterval))
+	}
+	if len(failed) > 0 {
+		logger.WithOptions(zap.WithCaller(false)).Warn("logger: failed to open the log files, falling back to stdout",
+			zap.Strings("filenames", failedNames), zap.Errors("errors", failedErrs))
+	}
+	l := Logger{sugared: logger.Sugar(), level: level, res: res}
+	if opt.HandleSIGHUP {
+		res.closers = append(res.closers, handleSIGHUP(l, errorOutput))
+	}
+	register(l)
+	return l, nil
+}
+
+// startSyncer syncs logger every interval until the returned closer is closed.
+func startSyncer(logger *zap.Logger, interval time.Duration) closerFunc {
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				// the errors are reported by the outputs on writing anyway.
+				_ = logger.Sync()
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// stopping is idempotent, so that a closed logger can be closed again.
+	var once sync.Once
+	return func() error {
+		once.Do(func() { close(stop) })
+		<-done
+		return nil
+	}
+}
+
+var (
+	hostnameOnce sync.Once
+	hostnameVal  string
+)
+
+// hostname returns the cached hostname, or "unknown" if it can not be resolved.
+func hostname() string {
+	hostnameOnce.Do(func() {
+		name, err := os.Hostname()
+		if err != nil {
+			name = "unknown"
+		}
+		hostnameVal = name
+	})
+	return hostnameVal
+}
+
+// Nop returns a logger which never writes out logs, it is handy in tests and
+// benchmarks.
+func Nop() Logger {
+	return Logger{sugared: zap.NewNop().Sugar(), level: zap.NewAtomicLevel(), res: &resources{output: &outputSwitch{}}}
 }
 
-var std = New(Options{Stdout: true, ConsoleMode: true})
+var (
+	// std holds the standard logger, it is swapped atomically so that
+	// SetOptions is safe while other goroutines are logging.
+	std atomic.Value
+
+	// stdMu serializes SetOptions.
+	stdMu sync.Mutex
+)
+
+func init() {
+	std.Store(New(Options{Stdout: true, ConsoleMode: true}))
+}
 
 // StandardLogger returns the standard logger with stdout output.
 func StandardLogger() Logger {
-	return std
+	return std.Load().(Logger)
 }
 
-// SetOptions sets the options for the standard logger.
-func SetOptions(opt Options) {
-	std = New(opt)
+// SetOptions sets the options for the standard logger and returns the
+// previous ones, which allows restoring them later:
+//
+//	prev := logger.SetOptions(debugOptions)
+//	defer logger.SetOptions(prev)
+//
+// The level set by SetLevel is reflected in the returned options. The previous
+// standard logger is closed after the new one takes over. It is
+// safe to call while other goroutines are logging, the entries they are
+// writing at the moment may still go to the previous logger.
+func SetOptions(opt Options) Options {
+	l := New(opt)
+
+	stdMu.Lock()
+	defer stdMu.Unlock()
+	prev := StandardLogger()
+	std.Store(l)
+	prev.Close()
+
+	return prev.Options()
 }
 
 // With adds a variadic number of fields to the logging context. It accepts a
 // mix of strongly-typed Field objects and loosely-typed key-value pairs. When
 // processing pairs, the first element of the pair is used as the field key
-// and the second as the field value.
+// and the second as the field value. It returns a child of the standard
+// logger, which itself is left unchanged.
 func With(args ...interface{}) Logger {
-	s := std
-	s.sugared = std.sugared.With(args...)
-	return s
+	return StandardLogger().With(args...)
+}
+
+// Named adds a sub-scope to the name of the standard logger.
+func Named(name string) Logger {
+	return StandardLogger().Named(name)
+}
+
+// GetOptions returns a copy of the options the standard logger was built with,
+// it is named so since Options is the type.
+func GetOptions() Options {
+	return StandardLogger().Options()
+}
+
+// Clone builds a new logger from the options of the standard logger modified
+// by modify, see Logger.Clone.
+func Clone(modify func(opt *Options)) Logger {
+	return StandardLogger().Clone(modify)
+}
+
+// SetLevel changes the logging level of the standard logger at runtime.
+func SetLevel(level Level) {
+	StandardLogger().SetLevel(level)
+}
+
+// GetLevel returns the current logging level of the standard logger.
+func GetLevel() Level {
+	return StandardLogger().GetLevel()
+}
+
+// Mute silences the standard logger until Unmute is called.
+func Mute() {
+	StandardLogger().Mute()
+}
+
+// Unmute restores the level the standard logger had before Mute.
+func Unmute() {
+	StandardLogger().Unmute()
+}
+
+// LevelHandler returns an HTTP handler which reports and changes the level of
+// the standard logger. The handler keeps working on the current standard
+// logger after SetOptions.
+func LevelHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		StandardLogger().LevelHandler().ServeHTTP(w, r)
+	})
+}
+
+// Enabled reports whether entries at the level would be written by the
+// standard logger.
+func Enabled(level Level) bool {
+	return StandardLogger().Enabled(level)
 }
 
 // Println is the alias for Info
 func Println(args ...interface{}) {
-	std.sugared.Info(args...)
+	StandardLogger().sugared.Info(args...)
 }
 
 // Printf is the alias for Infof
 func Printf(template string, args ...interface{}) {
-	std.sugared.Infof(template, args...)
+	StandardLogger().sugared.Infof(template, args...)
+}
+
+// Sync flushes any buffered log entries of the standard logger. Applications
+// should take care to call Sync before exiting, typically via
+// `defer logger.Sync()` in main.
+func Sync() error {
+	return StandardLogger().sugared.Sync()
+}
+
+// SetOutput redirects the primary output of the standard logger to w, like
+// log.SetOutput does. A nil w restores the original destination.
+func SetOutput(w io.Writer) {
+	StandardLogger().SetOutput(w)
+}
+
+// RecentLogs returns the most recent logs of the standard logger retained by
+// RingBufferSize.
+func RecentLogs() []string {
+	return StandardLogger().RecentLogs()
+}
+
+// ReopenFile reopens the File of the standard logger by its name.
+func ReopenFile() error {
+	return StandardLogger().ReopenFile()
+}
+
+// Rotate rotates the log files of the standard logger right away.
+func Rotate() error {
+	return StandardLogger().Rotate()
 }
 
 // Debug uses fmt.Sprint to construct and log a message.
 func Debug(args ...interface{}) {
-	std.sugared.Debug(args...)
+	StandardLogger().sugared.Debug(args...)
 }
 
 // Info uses fmt.Sprint to construct and log a message.
 func Info(args ...interface{}) {
-	std.sugared.Info(args...)
+	StandardLogger().sugared.Info(args...)
 }
 
 // Warn uses fmt.Sprint to construct and log a message.
 func Warn(args ...interface{}) {
-	std.sugared.Warn(args...)
+	StandardLogger().sugared.Warn(args...)
 }
 
 // Error uses fmt.Sprint to construct and log a message.
 func Error(args ...interface{}) {
-	std.sugared.Error(args...)
+	StandardLogger().sugared.Error(args...)
+}
+
+// DPanic uses fmt.Sprint to construct and log a message. In development, the
+// logger then panics.
+func DPanic(args ...interface{}) {
+	StandardLogger().sugared.DPanic(args...)
 }
 
 // Panic uses fmt.Sprint to construct and log a message, then panics.
 func Panic(args ...interface{}) {
-	std.sugared.Panic(args...)
+	StandardLogger().sugared.Panic(args...)
 }
 
 // Fatal uses fmt.Sprint to construct and log a message, then calls os.Exit.
 func Fatal(args ...interface{}) {
-	std.sugared.Fatal(args...)
+	StandardLogger().sugared.Fatal(args...)
 }
 
 // Debugf uses fmt.Sprintf to log a templated message.
 func Debugf(template string, args ...interface{}) {
-	std.sugared.Debugf(template, args...)
+	StandardLogger().sugared.Debugf(template, args...)
 }
 
 // Infof uses fmt.Sprintf to log a templated message.
 func Infof(template string, args ...interface{}) {
-	std.sugared.Infof(template, args...)
+	StandardLogger().sugared.Infof(template, args...)
 }
 
 // Warnf uses fmt.Sprintf to log a templated message.
 func Warnf(template string, args ...interface{}) {
-	std.sugared.Warnf(template, args...)
+	StandardLogger().sugared.Warnf(template, args...)
 }
 
 // Errorf uses fmt.Sprintf to log a templated message.
 func Errorf(template string, args ...interface{}) {
-	std.sugared.Errorf(template, args...)
+	StandardLogger().sugared.Errorf(template, args...)
+}
+
+// DPanicf uses fmt.Sprintf to log a templated message. In development, the
+// logger then panics.
+func DPanicf(template string, args ...interface{}) {
+	StandardLogger().sugared.DPanicf(template, args...)
 }
 
 // Panicf uses fmt.Sprintf to log a templated message, then panics.
 func Panicf(template string, args ...interface{}) {
-	std.sugared.Panicf(template, args...)
+	StandardLogger().sugared.Panicf(template, args...)
 }
 
 // Fatalf uses fmt.Sprintf to log a templated message, then calls os.Exit.
 func Fatalf(template string, args ...interface{}) {
-	std.sugared.Fatalf(template, args...)
+	StandardLogger().sugared.Fatalf(template, args...)
+}
+
+// Debugw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func Debugw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Debugw(msg, keysAndValues...)
+}
+
+// Infow logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func Infow(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Infow(msg, keysAndValues...)
+}
+
+// Warnw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func Warnw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Warnw(msg, keysAndValues...)
+}
+
+// Errorw logs a message with some additional context. The variadic
+// key-value pairs are treated as they are in With.
+func Errorw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Errorw(msg, keysAndValues...)
+}
+
+// DPanicw logs a message with some additional context. In development, the
+// logger then panics. The variadic key-value pairs are treated as they are in
+// With.
+func DPanicw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.DPanicw(msg, keysAndValues...)
+}
+
+// Panicw logs a message with some additional context, then panics. The variadic
+// key-value pairs are treated as they are in With.
+func Panicw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Panicw(msg, keysAndValues...)
+}
+
+// Fatalw logs a message with some additional context, then calls os.Exit. The variadic
+// key-value pairs are treated as they are in With.
+func Fatalw(msg string, keysAndValues ...interface{}) {
+	StandardLogger().sugared.Fatalw(msg, keysAndValues...)
 }
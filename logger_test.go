@@ -0,0 +1,317 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// decodeLines decodes every line of buf as a JSON object.
+func decodeLines(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+
+	var entries []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var entry map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatalf("invalid JSON %q: %v", line, err)
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// logConcurrently logs n entries from each of the goroutines.
+func logConcurrently(goroutines, n int, log func(g, i int)) {
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < n; i++ {
+				log(g, i)
+			}
+		}(g)
+	}
+	wg.Wait()
+}
+
+func TestWriterConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf})
+
+	logConcurrently(8, 100, func(g, i int) { l.Info("concurrent") })
+
+	if n := strings.Count(buf.String(), "\n"); n != 800 {
+		t.Fatalf("got %d lines, want 800", n)
+	}
+}
+
+func TestEmptyFilename(t *testing.T) {
+	dir := t.TempDir()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	for _, opt := range []Options{
+		{},
+		{Outputs: []OutputConfig{{Filename: ""}}, Stdout: true},
+		{LevelFiles: map[Level]string{WarnLevel: ""}, Stdout: true},
+	} {
+		if _, err := NewWithError(opt); !errors.Is(err, ErrNoFilename) {
+			t.Errorf("NewWithError(%+v) = %v, want ErrNoFilename", opt, err)
+		}
+		if err := opt.Validate(); !errors.Is(err, ErrNoFilename) {
+			t.Errorf("Validate(%+v) = %v, want ErrNoFilename", opt, err)
+		}
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, "*"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 0 {
+		t.Errorf("files created in the working directory: %v", files)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	syslog := SyslogConfig{Network: "udp", Address: "127.0.0.1:514"}
+	for name, opt := range map[string]Options{
+		"syslog network":  {Syslog: &SyslogConfig{Network: "unix", Address: syslog.Address}},
+		"syslog address":  {Syslog: &SyslogConfig{Network: syslog.Network}},
+		"syslog facility": {Syslog: &SyslogConfig{Network: syslog.Network, Address: syslog.Address, Facility: 24}},
+		"zero rate":       {RateLimit: &RateLimitConfig{Burst: 1}},
+		"negative rate":   {RateLimit: &RateLimitConfig{Rate: -1, Burst: 1}},
+	} {
+		opt.Writer = io.Discard
+		if err := opt.Validate(); err == nil {
+			t.Errorf("%s: Validate() = nil, want an error", name)
+		}
+		if _, err := NewWithError(opt); err == nil {
+			t.Errorf("%s: NewWithError() = nil error, want an error", name)
+		}
+	}
+
+	opt := Options{Writer: io.Discard, Syslog: &syslog, RateLimit: &RateLimitConfig{Rate: 1, Burst: 1}}
+	if err := opt.Validate(); err != nil {
+		t.Errorf("Validate() = %v, want nil", err)
+	}
+}
+
+func TestFallbackStdout(t *testing.T) {
+	dir := t.TempDir()
+	blocker := filepath.Join(dir, "blocker")
+	if err := os.WriteFile(blocker, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	stdout, err := os.Create(filepath.Join(dir, "stdout"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdout.Close()
+	orig := os.Stdout
+	os.Stdout = stdout
+	defer func() { os.Stdout = orig }()
+
+	l, err := NewWithError(Options{
+		Filename:       filepath.Join(dir, "app.log"),
+		ErrorFilename:  filepath.Join(blocker, "error.log"),
+		LevelFiles:     map[Level]string{DebugLevel: filepath.Join(blocker, "debug.log")},
+		Level:          DebugLevel,
+		FallbackStdout: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Debug("debug")
+	l.Info("info")
+	l.Error("error")
+	l.Close()
+	os.Stdout = orig
+
+	data, err := os.ReadFile(stdout.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the stdout output writes what either file would have written.
+	var msgs []string
+	for _, line := range decodeLines(t, bytes.NewBuffer(data)) {
+		msgs = append(msgs, line["msg"].(string))
+	}
+	if got, want := strings.Join(msgs, "|"), "debug|error"; got != want {
+		t.Errorf("stdout has %s, want %s:\n%s", got, want, data)
+	}
+
+	// a single warning lists the files, in the primary output since the
+	// failed ones are not written at WarnLevel.
+	data, err = os.ReadFile(filepath.Join(dir, "app.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var warnings int
+	for _, line := range decodeLines(t, bytes.NewBuffer(data)) {
+		if line["msg"] != "logger: failed to open the log files, falling back to stdout" {
+			continue
+		}
+		warnings++
+		if filenames, _ := line["filenames"].([]interface{}); len(filenames) != 2 {
+			t.Errorf("filenames = %v, want both files", line["filenames"])
+		}
+	}
+	if warnings != 1 {
+		t.Errorf("got %d warnings, want 1:\n%s", warnings, data)
+	}
+}
+
+func TestFullCaller(t *testing.T) {
+	for _, full := range []bool{false, true} {
+		var buf bytes.Buffer
+		l := New(Options{Writer: &buf, FullCaller: full})
+		_, file, line, _ := runtime.Caller(0)
+		l.Info("caller")
+
+		caller := decodeLines(t, &buf)[0]["caller"].(string)
+		want := fmt.Sprintf("%s/%s:%d", filepath.Base(filepath.Dir(file)), filepath.Base(file), line+1)
+		if full {
+			want = fmt.Sprintf("%s:%d", file, line+1)
+			if !filepath.IsAbs(strings.TrimSuffix(caller, fmt.Sprintf(":%d", line+1))) {
+				t.Errorf("FullCaller: caller %q is not an absolute path", caller)
+			}
+		}
+		if caller != want {
+			t.Errorf("FullCaller %v: caller = %q, want %q", full, caller, want)
+		}
+	}
+}
+
+func TestDisableCaller(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, DisableCaller: true})
+	l.Info("no caller")
+
+	if line := decodeLines(t, &buf)[0]; line["caller"] != nil {
+		t.Errorf("caller = %v, want none", line["caller"])
+	}
+}
+
+func TestDisableTime(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, DisableTime: true})
+	l.Info("no time")
+
+	line := decodeLines(t, &buf)[0]
+	if _, ok := line["ts"]; ok {
+		t.Errorf("ts = %v, want none", line["ts"])
+	}
+	if line["msg"] != "no time" {
+		t.Errorf("msg = %v, want no time", line["msg"])
+	}
+}
+
+func TestSetOutputConcurrent(t *testing.T) {
+	l := New(Options{Writer: io.Discard})
+	var buf bytes.Buffer
+	l.SetOutput(&buf)
+
+	logConcurrently(8, 100, func(g, i int) { l.Info("concurrent") })
+
+	if n := strings.Count(buf.String(), "\n"); n != 800 {
+		t.Fatalf("got %d lines, want 800", n)
+	}
+}
+
+// setStandardLogger replaces the standard logger for the test.
+func setStandardLogger(t *testing.T, opt Options) {
+	prev := SetOptions(opt)
+	t.Cleanup(func() { SetOptions(prev) })
+}
+
+func TestSetOptionsConcurrent(t *testing.T) {
+	setStandardLogger(t, Options{Writer: io.Discard})
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		levels := []Level{DebugLevel, InfoLevel, WarnLevel}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			SetOptions(Options{Writer: io.Discard, Level: levels[i%len(levels)]})
+			SetLevel(levels[(i+1)%len(levels)])
+		}
+	}()
+
+	logConcurrently(8, 500, func(g, i int) {
+		Infof("goroutine %d", g)
+		With("i", i).Warn("with")
+		_ = GetLevel()
+	})
+	close(stop)
+	<-done
+}
+
+func TestWithStandardLogger(t *testing.T) {
+	var buf bytes.Buffer
+	setStandardLogger(t, Options{Writer: &buf})
+
+	child := With("request_id", "abc")
+	child.Info("child")
+	Info("std")
+	StandardLogger().Info("std")
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3", len(lines))
+	}
+	if lines[0]["request_id"] != "abc" {
+		t.Errorf("child line = %v, want the request id abc", lines[0])
+	}
+	for _, line := range lines[1:] {
+		if _, ok := line["request_id"]; ok {
+			t.Errorf("standard logger line = %v, want no request id", line)
+		}
+	}
+}
+
+func TestBuildInfo(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, BuildInfo: BuildInfo{
+		Version:    "v1.2.3",
+		CommitTime: "2022-01-02T15:04:05Z",
+		BuildTime:  "2022-01-03T00:00:00Z",
+	}})
+	l.Info("built")
+
+	line := decodeLines(t, &buf)[0]
+	want := map[string]interface{}{
+		"version":     "v1.2.3",
+		"commit_time": "2022-01-02T15:04:05Z",
+		"build_time":  "2022-01-03T00:00:00Z",
+	}
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+	if _, ok := line["commit"]; ok {
+		t.Errorf("the empty commit is attached: %v", line)
+	}
+}
@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// requestIDHeader is the header carrying the request id.
+const requestIDHeader = "X-Request-Id"
+
+// HTTPMiddleware logs every request handled by next with its method, path,
+// status, duration and the number of bytes written. The requests answered
+// with a 5xx status are logged at ErrorLevel, the others at InfoLevel.
+//
+// The request id is taken from the X-Request-Id header, or generated if there
+// is none. A child logger carrying it as the "request_id" field is put into
+// the context of the request, so that the handlers can log with it:
+//
+//	logger.FromContext(r.Context()).Info("loading the user")
+func (l Logger) HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		id := r.Header.Get(requestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		child := l.With("request_id", id)
+
+		rw := &responseWriter{ResponseWriter: w, status: http.StatusOK}
+		next.ServeHTTP(rw, r.WithContext(child.WithContext(r.Context())))
+
+		// the caller would always be this function.
+		logger := child.sugared.Desugar().WithOptions(zap.WithCaller(false))
+		fields := []zap.Field{
+			zap.String("method", r.Method),
+			zap.String("path", r.URL.Path),
+			zap.Int("status", rw.status),
+			zap.Duration("duration", time.Since(start)),
+			zap.Int64("bytes", rw.bytes),
+		}
+		if rw.status >= http.StatusInternalServerError {
+			logger.Error("http request", fields...)
+		} else {
+			logger.Info("http request", fields...)
+		}
+	})
+}
+
+// HTTPMiddleware logs every request handled by next with the standard logger.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return StandardLogger().HTTPMiddleware(next)
+}
+
+// newRequestID returns a random id of 16 hex digits.
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// responseWriter records the status and the number of bytes written.
+type responseWriter struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+// WriteHeader records the status and sends it.
+func (w *responseWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status, w.wroteHeader = status, true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// Write counts the bytes written.
+func (w *responseWriter) Write(p []byte) (int, error) {
+	w.wroteHeader = true
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush sends the buffered data if the underlying writer supports it.
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		w.wroteHeader = true
+		f.Flush()
+	}
+}
+
+// Hijack takes over the connection if the underlying writer supports it, like
+// for a WebSocket upgrade.
+func (w *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := w.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("logger: %T does not implement http.Hijacker", w.ResponseWriter)
+	}
+	w.wroteHeader = true
+	return h.Hijack()
+}
+
+// Unwrap returns the underlying writer for http.ResponseController.
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
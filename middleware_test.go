@@ -0,0 +1,114 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf})
+	h := l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		w.Write([]byte("oops"))
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/users?id=1", nil)
+	r.Header.Set(requestIDHeader, "abc")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+	if lines[0]["msg"] != "handling" || lines[0]["request_id"] != "abc" {
+		t.Errorf("handler line = %v, want the request id abc", lines[0])
+	}
+	want := map[string]interface{}{
+		"level":      "ERROR",
+		"msg":        "http request",
+		"request_id": "abc",
+		"method":     http.MethodPost,
+		"path":       "/users",
+		"status":     float64(http.StatusServiceUnavailable),
+		"bytes":      float64(4),
+	}
+	for k, v := range want {
+		if lines[1][k] != v {
+			t.Errorf("request line %s = %v, want %v", k, lines[1][k], v)
+		}
+	}
+	if _, ok := lines[1]["caller"]; ok {
+		t.Errorf("request line has a caller: %v", lines[1])
+	}
+}
+
+func TestHTTPMiddlewareRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	h := New(Options{Writer: &buf}).HTTPMiddleware(http.NotFoundHandler())
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	line := decodeLines(t, &buf)[0]
+	if id, _ := line["request_id"].(string); len(id) != 16 {
+		t.Errorf("request_id = %v, want 16 hex digits", line["request_id"])
+	}
+	if line["level"] != "INFO" || line["status"] != float64(http.StatusNotFound) {
+		t.Errorf("request line = %v, want INFO with status 404", line)
+	}
+}
+
+func TestHTTPMiddlewareHijack(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf})
+	h := l.HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hj, ok := w.(http.Hijacker)
+		if !ok {
+			t.Error("the response writer does not implement http.Hijacker")
+			return
+		}
+		conn, rw, err := hj.Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 200 OK\r\nContent-Length: 2\r\nConnection: close\r\n\r\nok")
+		rw.Flush()
+	}))
+
+	// the request is logged after the handler returns, the connection may be
+	// closed before.
+	done := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer close(done)
+		h.ServeHTTP(w, r)
+	}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "ok" {
+		t.Errorf("body = %q, want ok", body)
+	}
+	<-done
+
+	if lines := decodeLines(t, &buf); len(lines) != 1 || lines[0]["msg"] != "http request" {
+		t.Errorf("lines = %v, want the request", lines)
+	}
+
+	rw := &responseWriter{ResponseWriter: httptest.NewRecorder()}
+	if _, _, err := rw.Hijack(); err == nil {
+		t.Error("Hijack() of a recorder = nil error, want an error")
+	}
+}
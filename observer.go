@@ -0,0 +1,46 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// LoggedEntry is an entry recorded by the logger returned by NewTest, along
+// with its fields.
+type LoggedEntry = observer.LoggedEntry
+
+// ObservedLogs is the collection of the entries recorded by the logger
+// returned by NewTest, it is safe for concurrent use.
+type ObservedLogs struct {
+	logs *observer.ObservedLogs
+}
+
+// All returns a copy of all the recorded entries.
+func (o *ObservedLogs) All() []LoggedEntry {
+	return o.logs.All()
+}
+
+// Len returns the number of the recorded entries.
+func (o *ObservedLogs) Len() int {
+	return o.logs.Len()
+}
+
+// FilterLevel returns the entries recorded at the given level.
+func (o *ObservedLogs) FilterLevel(level Level) *ObservedLogs {
+	return &ObservedLogs{logs: o.logs.FilterLevelExact(zapcore.Level(level))}
+}
+
+// FilterMessage returns the entries with the given message.
+func (o *ObservedLogs) FilterMessage(msg string) *ObservedLogs {
+	return &ObservedLogs{logs: o.logs.FilterMessage(msg)}
+}
+
+// NewTest returns a logger which records the entries at DebugLevel or above
+// in memory instead of writing them out, so that tests can assert on them.
+func NewTest() (Logger, *ObservedLogs) {
+	level := zap.NewAtomicLevelAt(zapcore.DebugLevel)
+	core, logs := observer.New(level)
+	logger := zap.New(core, zap.AddCaller(), zap.AddCallerSkip(1))
+	return Logger{sugared: logger.Sugar(), level: level, res: &resources{output: &outputSwitch{}}}, &ObservedLogs{logs: logs}
+}
@@ -0,0 +1,343 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// ErrNoFilename is returned when an output writes to a file but no Filename is
+// given.
+var ErrNoFilename = errors.New("logger: Filename is required unless writing to a writer, stdout or stderr")
+
+// OutputConfig is the option set for an output of Logger.
+type OutputConfig struct {
+	// Writer is the destination of logs, it takes precedence over Stdout and
+	// Filename if it is not nil.
+	Writer io.Writer `json:"-" yaml:"-"`
+
+	// Stdout sets the writer as stdout if it is true.
+	Stdout bool `json:"stdout,omitempty" yaml:"stdout,omitempty"`
+
+	// Stderr sets the writer as stderr if it is true. If both Stdout and Stderr
+	// are true, entries at ErrorLevel or above go to stderr and the rest go to
+	// stdout.
+	Stderr bool `json:"stderr,omitempty" yaml:"stderr,omitempty"`
+
+	// File is an open file to write logs to without rotation, it takes
+	// precedence over Filename.
+	File *os.File `json:"-" yaml:"-"`
+
+	// ConsoleMode sets the output to use the console encoder instead of the JSON one.
+	ConsoleMode bool `json:"console_mode,omitempty" yaml:"console_mode,omitempty"`
+
+	// Format is the encoding of the output, it takes precedence over
+	// ConsoleMode if it is not empty.
+	Format Format `json:"format,omitempty" yaml:"format,omitempty"`
+
+	// Filename is the file to write logs to. It is ignored if Stdout or Stderr
+	// is true.
+	Filename string `json:"filename,omitempty" yaml:"filename,omitempty"`
+
+	// MaxSize is the maximum size in megabytes of the log file before it gets rotated.
+	MaxSize int `json:"max_size,omitempty" yaml:"max_size,omitempty"`
+
+	// MaxAge is the maximum number of days to retain old log files, zero
+	// retains them regardless of age.
+	MaxAge int `json:"max_age,omitempty" yaml:"max_age,omitempty"`
+
+	// MaxBackups is the maximum number of old log files to retain, zero
+	// retains all of them.
+	MaxBackups int `json:"max_backups,omitempty" yaml:"max_backups,omitempty"`
+
+	// Compress determines if the rotated log files should be compressed using gzip.
+	Compress bool `json:"compress,omitempty" yaml:"compress,omitempty"`
+
+	// Shards splits Filename into the given number of files written in turn
+	// if it is greater than 1, see Options.Shards.
+	Shards int `json:"shards,omitempty" yaml:"shards,omitempty"`
+
+	// Level is the minimum logging priority of the output. Entries have to be
+	// enabled by the level of the logger as well.
+	Level Level `json:"level,omitempty" yaml:"level,omitempty"`
+
+	// ExactLevel makes the output only write the entries at exactly Level.
+	ExactLevel bool `json:"exact_level,omitempty" yaml:"exact_level,omitempty"`
+
+	// Audit makes Filename an append-only file which is never rotated, so no
+	// entry is ever removed, and which is synced to disk after every entry at
+	// ErrorLevel or above. MaxSize, MaxAge, MaxBackups and Compress can't be
+	// set along with it, the file is not buffered by Options.Buffer either.
+	Audit bool `json:"audit,omitempty" yaml:"audit,omitempty"`
+
+	// sw redirects the output once SetOutput is called, it is only set on the
+	// primary output of the logger.
+	sw *outputSwitch
+
+	// fallbackOf are the outputs whose files failed to open, the stdout output
+	// replacing them writes the entries any of them would have written.
+	fallbackOf []OutputConfig
+}
+
+// newCore builds the core writing to the output, opt carries the settings
+// shared by all the outputs of the logger. The files opened by the output are
+// added to res.
+func (o OutputConfig) newCore(opt Options, encoderConfig zapcore.EncoderConfig, level zap.AtomicLevel, res *resources) (zapcore.Core, error) {
+	enabler := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+		return o.enables(lvl) && level.Enabled(lvl)
+	})
+
+	if o.Writer == nil && o.Stdout && o.Stderr {
+		low := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl < zapcore.ErrorLevel && enabler(lvl)
+		})
+		high := zap.LevelEnablerFunc(func(lvl zapcore.Level) bool {
+			return lvl >= zapcore.ErrorLevel && enabler(lvl)
+		})
+		stdout, stderr := consoleSyncer{os.Stdout}, consoleSyncer{os.Stderr}
+		stdoutEncoder, err := o.newEncoder(opt, encoderConfig, stdout)
+		if err != nil {
+			return nil, err
+		}
+		stderrEncoder, err := o.newEncoder(opt, encoderConfig, stderr)
+		if err != nil {
+			return nil, err
+		}
+		return zapcore.NewTee(
+			wrapCore(zapcore.NewCore(stdoutEncoder, o.switched(stdout), low), opt),
+			wrapCore(zapcore.NewCore(stderrEncoder, o.switched(stderr), high), opt),
+		), nil
+	}
+
+	if o.Audit {
+		w, err := newAuditFile(o.Filename, res)
+		if err != nil {
+			return nil, err
+		}
+		encoder, err := o.newEncoder(opt, encoderConfig, w)
+		if err != nil {
+			return nil, err
+		}
+		return wrapCore(newAuditCore(zapcore.NewCore(encoder, w, enabler), w), opt), nil
+	}
+
+	w, err := o.newWriteSyncer(opt, res)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := o.newEncoder(opt, encoderConfig, w)
+	if err != nil {
+		return nil, err
+	}
+	return wrapCore(zapcore.NewCore(encoder, o.switched(w), enabler), opt), nil
+}
+
+// switched makes w redirectable if the output is the primary one. It is
+// applied after the encoder is chosen, which depends on the type of w.
+func (o OutputConfig) switched(w zapcore.WriteSyncer) zapcore.WriteSyncer {
+	if o.sw == nil {
+		return w
+	}
+	return switchedSyncer{WriteSyncer: w, sw: o.sw}
+}
+
+// validate checks the settings of the output.
+func (o OutputConfig) validate() error {
+	if o.Level < DebugLevel || o.Level > OffLevel {
+		return fmt.Errorf("logger: invalid level %d", o.Level)
+	}
+
+	switch f := o.format(); f {
+	case JSONFormat, ConsoleFormat, LogfmtFormat:
+	default:
+		return fmt.Errorf("logger: unknown format %q", f)
+	}
+
+	if !o.toFile() {
+		if o.Audit {
+			return errors.New("logger: Audit only applies to Filename")
+		}
+		return nil
+	}
+	if o.Filename == "" {
+		return ErrNoFilename
+	}
+	if o.Audit && (o.MaxSize != 0 || o.MaxAge != 0 || o.MaxBackups != 0 || o.Compress || o.Shards > 1) {
+		return fmt.Errorf("logger: audit file %s can't be rotated by MaxSize, MaxAge, MaxBackups or Compress, or sharded", o.Filename)
+	}
+	if o.MaxSize < 0 || o.MaxAge < 0 {
+		return fmt.Errorf("logger: MaxSize and MaxAge of %s must not be negative", o.Filename)
+	}
+	if o.Shards < 0 {
+		return fmt.Errorf("logger: Shards of %s must not be negative", o.Filename)
+	}
+	if o.MaxBackups < 0 {
+		return fmt.Errorf("logger: MaxBackups of %s is negative, use 0 to retain all the backups", o.Filename)
+	}
+	return nil
+}
+
+// enables reports whether the output writes the entries at lvl, regardless of
+// the level of the logger.
+func (o OutputConfig) enables(lvl zapcore.Level) bool {
+	if len(o.fallbackOf) > 0 {
+		for _, f := range o.fallbackOf {
+			if f.enables(lvl) {
+				return true
+			}
+		}
+		return false
+	}
+	if o.ExactLevel {
+		return lvl == zapcore.Level(o.Level)
+	}
+	return lvl >= zapcore.Level(o.Level)
+}
+
+// stdoutFallback returns the output writing to stdout in place of the files
+// of the failed outputs, the first of which provides the encoding.
+func stdoutFallback(failed []OutputConfig) OutputConfig {
+	o := failed[0]
+	return OutputConfig{
+		Stdout:      true,
+		ConsoleMode: o.ConsoleMode,
+		Format:      o.Format,
+		sw:          o.sw,
+		fallbackOf:  failed,
+	}
+}
+
+// toFile reports whether the output writes to Filename.
+func (o OutputConfig) toFile() bool {
+	return o.Writer == nil && !o.Stdout && !o.Stderr && o.File == nil
+}
+
+// format returns the encoding of the output.
+func (o OutputConfig) format() Format {
+	if o.Format != "" {
+		return o.Format
+	}
+	if o.ConsoleMode {
+		return ConsoleFormat
+	}
+	return JSONFormat
+}
+
+// newEncoder returns the encoder of the output writing to w.
+func (o OutputConfig) newEncoder(opt Options, encoderConfig zapcore.EncoderConfig, w zapcore.WriteSyncer) (zapcore.Encoder, error) {
+	if opt.Encoder != nil {
+		// every core needs its own encoder to add fields to.
+		return opt.Encoder.Clone(), nil
+	}
+
+	switch f := o.format(); f {
+	case JSONFormat:
+		enc := zapcore.NewJSONEncoder(encoderConfig)
+		if opt.SortKeys {
+			enc = newSortedEncoder(enc, encoderConfig)
+		}
+		if _, ok := w.(consoleSyncer); ok && opt.PrettyJSON {
+			enc = newPrettyEncoder(enc)
+		}
+		return enc, nil
+	case ConsoleFormat:
+		if opt.Color && isTerminal(w) {
+			encoderConfig.EncodeLevel = opt.LevelEncoder.colored().zap()
+		}
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case LogfmtFormat:
+		return newLogfmtEncoder(encoderConfig), nil
+	default:
+		return nil, fmt.Errorf("logger: unknown format %q", f)
+	}
+}
+
+// newWriteSyncer opens the destination of the output, which has been
+// validated.
+func (o OutputConfig) newWriteSyncer(opt Options, res *resources) (zapcore.WriteSyncer, error) {
+	if o.Writer != nil {
+		// the cores don't serialize the writes, while most writers like
+		// bytes.Buffer are not safe for concurrent use.
+		return zapcore.Lock(zapcore.AddSync(o.Writer)), nil
+	}
+
+	if o.Stdout {
+		return consoleSyncer{os.Stdout}, nil
+	}
+
+	if o.Stderr {
+		return consoleSyncer{os.Stderr}, nil
+	}
+
+	if o.File != nil {
+		file := &reopenableFile{file: o.File}
+		res.opened = append(res.opened, file)
+		res.closers = append(res.closers, file)
+		return file, nil
+	}
+
+	if o.Shards > 1 {
+		shards := make([]zapcore.WriteSyncer, o.Shards)
+		for i := range shards {
+			shard := o
+			shard.Filename, shard.Shards = shardName(o.Filename, i), 0
+			w, err := shard.newWriteSyncer(opt, res)
+			if err != nil {
+				return nil, err
+			}
+			shards[i] = w
+		}
+		return &shardedSyncer{shards: shards}, nil
+	}
+
+	if file := res.sharedFile(o.Filename); file != nil {
+		return file, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(o.Filename), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	// lumberjack opens the file lazily on the first write, try it here so
+	// that a bad path is reported at construction time.
+	f, err := os.OpenFile(o.Filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, err
+	}
+	f.Close()
+
+	array := opt.JSONArray && opt.Encoder == nil && o.format() == JSONFormat
+	file := newRotatingFile(&lumberjack.Logger{
+		Filename:   o.Filename,
+		MaxSize:    o.MaxSize,
+		MaxBackups: o.MaxBackups,
+		MaxAge:     o.MaxAge,
+		Compress:   o.Compress,
+		LocalTime:  !opt.UTC,
+	}, opt, array)
+	res.files = append(res.files, file)
+	if opt.Buffer == nil {
+		res.closers = append(res.closers, file)
+		return file, nil
+	}
+
+	ws := &zapcore.BufferedWriteSyncer{
+		WS:            file,
+		Size:          opt.Buffer.Size,
+		FlushInterval: opt.Buffer.FlushInterval,
+	}
+	res.closers = append(res.closers, closerFunc(func() error {
+		err := ws.Stop()
+		if cerr := file.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+		return err
+	}))
+	return ws, nil
+}
@@ -0,0 +1,197 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RateLimitConfig is the option set for rate limiting. Every message has its
+// own token bucket, so that a noisy message is throttled while the others
+// pass freely.
+type RateLimitConfig struct {
+	// Rate is the number of entries per second allowed for each message.
+	Rate float64 `json:"rate,omitempty" yaml:"rate,omitempty"`
+
+	// Burst is the maximum number of entries allowed at once for each message,
+	// it defaults to Rate rounded up.
+	Burst int `json:"burst,omitempty" yaml:"burst,omitempty"`
+
+	// SummaryInterval is how often a summary of the suppressed entries is
+	// logged, it defaults to 10 seconds.
+	SummaryInterval time.Duration `json:"summary_interval,omitempty" yaml:"summary_interval,omitempty"`
+}
+
+// validate checks that the rate is positive, a zero rate would drop every
+// entry beyond the burst for good.
+func (cfg RateLimitConfig) validate() error {
+	if cfg.Rate <= 0 {
+		return fmt.Errorf("logger: rate limit must be positive, got %g", cfg.Rate)
+	}
+	if cfg.Burst < 0 {
+		return fmt.Errorf("logger: invalid rate limit burst %d", cfg.Burst)
+	}
+	if cfg.SummaryInterval < 0 {
+		return fmt.Errorf("logger: invalid rate limit summary interval %s", cfg.SummaryInterval)
+	}
+	return nil
+}
+
+// rateLimitCore drops the entries whose message runs out of tokens.
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+// newRateLimitCore wraps core and starts the goroutine logging the summaries,
+// which is stopped by closing the returned closer.
+func newRateLimitCore(core zapcore.Core, cfg RateLimitConfig) (*rateLimitCore, closerFunc) {
+	burst := cfg.Burst
+	if burst <= 0 {
+		burst = int(math.Ceil(cfg.Rate))
+	}
+	interval := cfg.SummaryInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+
+	limiter := &rateLimiter{
+		rate:    cfg.Rate,
+		burst:   float64(burst),
+		buckets: make(map[string]*tokenBucket),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go limiter.run(interval)
+
+	// stopping is idempotent, so that a closed logger can be closed again.
+	var once sync.Once
+	stop := func() error {
+		once.Do(func() { close(limiter.stop) })
+		<-limiter.done
+		return nil
+	}
+	return &rateLimitCore{Core: core, limiter: limiter}, stop
+}
+
+// With adds fields to the core, the limiter is shared with the derived core.
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+// Check drops the entry if its message is throttled.
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) || !c.limiter.allow(ent.Message, ent.Time, c.Core) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+type tokenBucket struct {
+	tokens     float64
+	last       time.Time
+	suppressed int
+
+	// core is the one of the logger which suppressed the last entry, the
+	// summary is written through it to carry the fields of that logger.
+	core zapcore.Core
+}
+
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// allow takes a token from the bucket of msg, core is the one the entry would
+// be written to.
+func (r *rateLimiter) allow(msg string, now time.Time, core zapcore.Core) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, ok := r.buckets[msg]
+	if !ok {
+		b = &tokenBucket{tokens: r.burst, last: now}
+		r.buckets[msg] = b
+	}
+	r.refill(b, now)
+
+	if b.tokens < 1 {
+		b.suppressed++
+		b.core = core
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (r *rateLimiter) refill(b *tokenBucket, now time.Time) {
+	if elapsed := now.Sub(b.last); elapsed > 0 {
+		b.tokens = math.Min(r.burst, b.tokens+elapsed.Seconds()*r.rate)
+		b.last = now
+	}
+}
+
+// run logs the summaries every interval until the limiter is stopped, the
+// pending summaries are logged before it returns.
+func (r *rateLimiter) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			r.summarize()
+		case <-r.stop:
+			r.summarize()
+			return
+		}
+	}
+}
+
+// summarize logs how many entries of each message were suppressed, and drops
+// the buckets which are full again to keep the memory bounded.
+func (r *rateLimiter) summarize() {
+	type summary struct {
+		n    int
+		core zapcore.Core
+	}
+	now := time.Now()
+	suppressed := make(map[string]summary)
+
+	r.mu.Lock()
+	for msg, b := range r.buckets {
+		if b.suppressed > 0 {
+			suppressed[msg] = summary{n: b.suppressed, core: b.core}
+			b.suppressed, b.core = 0, nil
+			continue
+		}
+		r.refill(b, now)
+		if b.tokens >= r.burst {
+			delete(r.buckets, msg)
+		}
+	}
+	r.mu.Unlock()
+
+	for msg, s := range suppressed {
+		ent := zapcore.Entry{
+			Level:   zapcore.WarnLevel,
+			Time:    now,
+			Message: fmt.Sprintf("suppressed %d messages", s.n),
+		}
+		if ce := s.core.Check(ent, nil); ce != nil {
+			ce.Write(zap.String("suppressed_msg", msg), zap.Int("suppressed", s.n))
+		}
+	}
+}
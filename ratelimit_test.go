@@ -0,0 +1,93 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestRateLimitConcurrent(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{
+		Writer:    &buf,
+		RateLimit: &RateLimitConfig{Rate: 0.001, Burst: 5, SummaryInterval: time.Hour},
+	})
+
+	logConcurrently(8, 100, func(g, i int) {
+		l.Info("noisy")
+		if i < 3 {
+			l.Infof("quiet %d", g)
+		}
+	})
+	// the pending summary is logged on closing.
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	counts := make(map[string]int)
+	var summaries []map[string]interface{}
+	for _, entry := range decodeLines(t, &buf) {
+		msg := entry["msg"].(string)
+		if entry["suppressed_msg"] != nil {
+			summaries = append(summaries, entry)
+			continue
+		}
+		counts[msg]++
+	}
+
+	if counts["noisy"] != 5 {
+		t.Errorf("noisy logged %d times, want the burst of 5", counts["noisy"])
+	}
+	for g := 0; g < 8; g++ {
+		if msg := fmt.Sprintf("quiet %d", g); counts[msg] != 3 {
+			t.Errorf("%q logged %d times, want 3", msg, counts[msg])
+		}
+	}
+
+	if len(summaries) != 1 {
+		t.Fatalf("got %d summaries, want 1: %v", len(summaries), summaries)
+	}
+	summary := summaries[0]
+	if summary["level"] != "WARN" || summary["msg"] != "suppressed 795 messages" ||
+		summary["suppressed_msg"] != "noisy" || summary["suppressed"] != 795.0 {
+		t.Errorf("unexpected summary %v", summary)
+	}
+}
+
+func TestRateLimitSummaryDerived(t *testing.T) {
+	var buf bytes.Buffer
+	var writes, warnings int32
+	l := New(Options{
+		Writer:      &buf,
+		ServiceName: "api",
+		RateLimit:   &RateLimitConfig{Rate: 0.001, Burst: 1, SummaryInterval: time.Hour},
+		OnWrite: func(level Level) {
+			atomic.AddInt32(&writes, 1)
+			if level == WarnLevel {
+				atomic.AddInt32(&warnings, 1)
+			}
+		},
+	})
+
+	child := l.With("request_id", "abc")
+	for i := 0; i < 3; i++ {
+		child.Info("noisy")
+	}
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := decodeLines(t, &buf)
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want the entry and the summary:\n%s", len(lines), buf.String())
+	}
+	summary := lines[1]
+	if summary["suppressed"] != 2.0 || summary["request_id"] != "abc" || summary["service"] != "api" {
+		t.Errorf("summary = %v, want 2 suppressed with the fields of the child", summary)
+	}
+	if writes != 2 || warnings != 1 {
+		t.Errorf("OnWrite called %d times with %d warnings, want 2 and 1", writes, warnings)
+	}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// Recover recovers from a panic and logs the recovered value at ErrorLevel,
+// along with the stacktrace of the panic. It must be deferred directly:
+//
+//	defer l.Recover(false)
+//
+// The panic is raised again with the same value if repanic is true, so that
+// it is logged before the program crashes.
+func (l Logger) Recover(repanic bool) {
+	if r := recover(); r != nil {
+		l.logPanic(r, repanic)
+	}
+}
+
+// logPanic logs the recovered value with the stack of the panicking goroutine,
+// which is still unwound up to the panic while the deferred call runs.
+func (l Logger) logPanic(r interface{}, repanic bool) {
+	// the caller of a deferred call is the runtime, and the stacktrace is
+	// attached by hand so that it isn't added twice.
+	logger := l.sugared.Desugar().WithOptions(
+		zap.WithCaller(false),
+		zap.AddStacktrace(zap.LevelEnablerFunc(func(zapcore.Level) bool { return false })),
+	)
+	// skip logPanic and Recover.
+	logger.Error("panic recovered", zap.Any("panic", r), zap.StackSkip("stacktrace", 2))
+	if repanic {
+		panic(r)
+	}
+}
+
+// Recover recovers from a panic and logs it with the standard logger. It must
+// be deferred directly.
+func Recover(repanic bool) {
+	if r := recover(); r != nil {
+		StandardLogger().logPanic(r, repanic)
+	}
+}
@@ -0,0 +1,457 @@
+package logger
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// redactedMask replaces the values of the masked fields.
+const redactedMask = "***"
+
+// Redacted constructs a field with the given key and a struct value, where
+// the struct fields tagged `log:"-"` are omitted and the ones tagged
+// `log:"mask"` are masked as "***":
+//
+//	type User struct {
+//		Name     string `json:"name"`
+//		Password string `json:"-" log:"-"`
+//		Email    string `json:"email" log:"mask"`
+//	}
+//
+//	logger.InfoFields("signed in", logger.Redacted("user", user))
+//
+// The values are encoded as encoding/json does: the keys follow the json tags,
+// the fields of the embedded structs are promoted, the byte slices are
+// encoded in base64 and the nil slices as null. The structs reached through
+// the fields, pointers, interfaces, slices, arrays and map values are
+// sanitized as well, except for the values encoding themselves as a
+// zapcore.ObjectMarshaler, a json.Marshaler or an encoding.TextMarshaler,
+// whose tags are not looked at. A cyclic value is encoded up to the cycle,
+// which is reported as an error.
+//
+// Walking the struct is slower than a hand-written zapcore.ObjectMarshaler,
+// though the tags of each type are only parsed once and cached.
+func Redacted(key string, v interface{}) Field {
+	rv := reflect.ValueOf(v)
+	if isNil(rv) {
+		return zap.Reflect(key, v)
+	}
+	switch m := v.(type) {
+	case zapcore.ObjectMarshaler:
+		return zap.Object(key, m)
+	case zapcore.ArrayMarshaler:
+		return zap.Array(key, m)
+	case json.Marshaler, encoding.TextMarshaler:
+		return zap.Reflect(key, m)
+	}
+
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch {
+	case isBytes(rv):
+		return zap.Binary(key, rv.Bytes())
+	case rv.Kind() == reflect.Slice && rv.IsNil():
+		return zap.Reflect(key, nil)
+	case rv.Kind() == reflect.Struct:
+		return zap.Object(key, redactedStruct{v: rv})
+	case rv.Kind() == reflect.Slice, rv.Kind() == reflect.Array:
+		return zap.Array(key, redactedSlice{v: rv})
+	case rv.Kind() == reflect.Map && !rv.IsNil():
+		return zap.Object(key, redactedMap{v: rv})
+	default:
+		return zap.Any(key, v)
+	}
+}
+
+// redactedPath holds the structs, slices and maps being walked, so that a
+// cycle is caught rather than walked until the stack overflows. The values
+// are removed once walked, so the values shared by several fields are not
+// mistaken for cycles.
+type redactedPath map[redactedRef]struct{}
+
+// redactedRef identifies a value by its address and type, since a struct and
+// its first field share the address. The length tells apart the slices of the
+// same array.
+type redactedRef struct {
+	ptr uintptr
+	len int
+	typ reflect.Type
+}
+
+// enter adds v to the path and returns the function removing it, or an error
+// if v is being walked already.
+func (p redactedPath) enter(v reflect.Value) (func(), error) {
+	var ref redactedRef
+	switch v.Kind() {
+	case reflect.Struct:
+		if !v.CanAddr() {
+			// a struct held by value can't be part of a cycle.
+			return func() {}, nil
+		}
+		ref = redactedRef{ptr: v.UnsafeAddr(), typ: v.Type()}
+	case reflect.Slice, reflect.Map:
+		ref = redactedRef{ptr: v.Pointer(), typ: v.Type()}
+		if v.Kind() == reflect.Slice {
+			ref.len = v.Len()
+		}
+	default:
+		return func() {}, nil
+	}
+
+	if _, ok := p[ref]; ok {
+		return nil, fmt.Errorf("logger: cyclic value of type %s", v.Type())
+	}
+	p[ref] = struct{}{}
+	return func() { delete(p, ref) }, nil
+}
+
+// redactedField is the parsed tags of a struct field.
+type redactedField struct {
+	index  []int // of the field and the embedded structs it is promoted from.
+	key    string
+	mask   bool
+	tagged bool // the key is taken from the json tag.
+}
+
+// redactedFields caches the parsed fields of the struct types.
+var redactedFields sync.Map // map[reflect.Type][]redactedField
+
+func redactedFieldsOf(t reflect.Type) []redactedField {
+	if fields, ok := redactedFields.Load(t); ok {
+		return fields.([]redactedField)
+	}
+
+	all := appendRedactedFields(nil, t, nil, false, make(map[reflect.Type]bool))
+	// a key taken by several fields goes to the least nested one, or to the
+	// one tagged with it at the same depth, like encoding/json does. The key
+	// is dropped if that leaves more than one field.
+	byKey := make(map[string][]redactedField)
+	for _, f := range all {
+		byKey[f.key] = append(byKey[f.key], f)
+	}
+	var fields []redactedField
+	for _, f := range all {
+		if dominant, ok := dominantRedactedField(byKey[f.key]); ok && sameIndex(dominant.index, f.index) {
+			fields = append(fields, f)
+		}
+	}
+	redactedFields.Store(t, fields)
+	return fields
+}
+
+// appendRedactedFields appends the fields of t, and the ones promoted from its
+// embedded structs. The index of the fields starts with index, and they are
+// masked if mask is true. seen holds the embedding structs, which can't be
+// promoted into themselves.
+func appendRedactedFields(fields []redactedField, t reflect.Type, index []int, mask bool, seen map[reflect.Type]bool) []redactedField {
+	if seen[t] {
+		return fields
+	}
+	seen[t] = true
+	defer delete(seen, t)
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("log")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(sf.Tag.Get("json"), ",")[0]
+		fieldIndex := append(index[:len(index):len(index)], i)
+		fieldMask := mask || tag == "mask"
+
+		if sf.Anonymous {
+			ft := sf.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			// the exported fields of an embedded struct are promoted, even
+			// if its type is unexported.
+			if name == "" && ft.Kind() == reflect.Struct {
+				fields = appendRedactedFields(fields, ft, fieldIndex, fieldMask, seen)
+				continue
+			}
+		}
+		if sf.PkgPath != "" {
+			continue
+		}
+
+		key, tagged := sf.Name, false
+		if name == "-" {
+			// the value is hidden from JSON, unless it is masked.
+			if !fieldMask {
+				continue
+			}
+		} else if name != "" {
+			key, tagged = name, true
+		}
+		fields = append(fields, redactedField{index: fieldIndex, key: key, mask: fieldMask, tagged: tagged})
+	}
+	return fields
+}
+
+// dominantRedactedField returns the field which takes the key shared by
+// fields, if any.
+func dominantRedactedField(fields []redactedField) (redactedField, bool) {
+	depth := len(fields[0].index)
+	for _, f := range fields {
+		if len(f.index) < depth {
+			depth = len(f.index)
+		}
+	}
+	var candidates, tagged []redactedField
+	for _, f := range fields {
+		if len(f.index) == depth {
+			candidates = append(candidates, f)
+			if f.tagged {
+				tagged = append(tagged, f)
+			}
+		}
+	}
+	switch {
+	case len(candidates) == 1:
+		return candidates[0], true
+	case len(tagged) == 1:
+		return tagged[0], true
+	default:
+		return redactedField{}, false
+	}
+}
+
+func sameIndex(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// redactedFieldByIndex returns the field of v at index, or false if it is
+// promoted through a nil pointer.
+func redactedFieldByIndex(v reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return reflect.Value{}, false
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+// redactedStruct marshals a struct without the omitted fields.
+type redactedStruct struct {
+	v    reflect.Value
+	path redactedPath // nil for the value passed to Redacted.
+}
+
+// MarshalLogObject adds the fields of the struct.
+func (s redactedStruct) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	// every encoding of the field walks the value on its own path, since a
+	// field may be encoded by several goroutines.
+	if s.path == nil {
+		s.path = make(redactedPath)
+	}
+	leave, err := s.path.enter(s.v)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	for _, f := range redactedFieldsOf(s.v.Type()) {
+		v, ok := redactedFieldByIndex(s.v, f.index)
+		if !ok {
+			continue
+		}
+		if f.mask {
+			enc.AddString(f.key, redactedMask)
+			continue
+		}
+		if err := addRedacted(enc, f.key, v, s.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedSlice marshals a slice whose elements are sanitized.
+type redactedSlice struct {
+	v    reflect.Value
+	path redactedPath // nil for the value passed to Redacted.
+}
+
+// MarshalLogArray appends the elements of the slice.
+func (s redactedSlice) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	if s.path == nil {
+		s.path = make(redactedPath)
+	}
+	leave, err := s.path.enter(s.v)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	for i := 0; i < s.v.Len(); i++ {
+		v := s.v.Index(i)
+		var err error
+		switch m := valueInterface(v).(type) {
+		case zapcore.ObjectMarshaler:
+			err = enc.AppendObject(m)
+		case zapcore.ArrayMarshaler:
+			err = enc.AppendArray(m)
+		case json.Marshaler, encoding.TextMarshaler:
+			err = enc.AppendReflected(m)
+		default:
+			err = appendRedacted(enc, indirect(v), s.path)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedMap marshals a map whose values are sanitized, in the order of the
+// keys.
+type redactedMap struct {
+	v    reflect.Value
+	path redactedPath // nil for the value passed to Redacted.
+}
+
+// MarshalLogObject adds the entries of the map.
+func (m redactedMap) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if m.path == nil {
+		m.path = make(redactedPath)
+	}
+	leave, err := m.path.enter(m.v)
+	if err != nil {
+		return err
+	}
+	defer leave()
+
+	keys := make([]string, 0, m.v.Len())
+	values := make(map[string]reflect.Value, m.v.Len())
+	iter := m.v.MapRange()
+	for iter.Next() {
+		key, err := redactedMapKey(iter.Key())
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+		values[key] = iter.Value()
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if err := addRedacted(enc, key, values[key], m.path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// redactedMapKey formats the key of a map entry as encoding/json does.
+func redactedMapKey(k reflect.Value) (string, error) {
+	if k.Kind() == reflect.String {
+		return k.String(), nil
+	}
+	if tm, ok := valueInterface(k).(encoding.TextMarshaler); ok {
+		b, err := tm.MarshalText()
+		return string(b), err
+	}
+	return fmt.Sprint(valueInterface(k)), nil
+}
+
+// appendRedacted appends the element, sanitizing the nested structs.
+func appendRedacted(enc zapcore.ArrayEncoder, elem reflect.Value, path redactedPath) error {
+	switch {
+	case isBytes(elem), elem.Kind() == reflect.Slice && elem.IsNil():
+		return enc.AppendReflected(valueInterface(elem))
+	case elem.Kind() == reflect.Struct:
+		return enc.AppendObject(redactedStruct{elem, path})
+	case elem.Kind() == reflect.Slice, elem.Kind() == reflect.Array:
+		return enc.AppendArray(redactedSlice{elem, path})
+	case elem.Kind() == reflect.Map && !elem.IsNil():
+		return enc.AppendObject(redactedMap{elem, path})
+	default:
+		return enc.AppendReflected(valueInterface(elem))
+	}
+}
+
+// addRedacted adds the value, sanitizing the nested structs. The values which
+// encode themselves, like time.Time, are not walked.
+func addRedacted(enc zapcore.ObjectEncoder, key string, v reflect.Value, path redactedPath) error {
+	if isNil(v) {
+		return enc.AddReflected(key, nil)
+	}
+	switch m := valueInterface(v).(type) {
+	case zapcore.ObjectMarshaler:
+		return enc.AddObject(key, m)
+	case zapcore.ArrayMarshaler:
+		return enc.AddArray(key, m)
+	case json.Marshaler, encoding.TextMarshaler:
+		return enc.AddReflected(key, m)
+	}
+
+	elem := indirect(v)
+	switch {
+	case isBytes(elem):
+		enc.AddBinary(key, elem.Bytes())
+		return nil
+	case elem.Kind() == reflect.Struct:
+		return enc.AddObject(key, redactedStruct{elem, path})
+	case elem.Kind() == reflect.Slice && !elem.IsNil(), elem.Kind() == reflect.Array:
+		return enc.AddArray(key, redactedSlice{elem, path})
+	case elem.Kind() == reflect.Map && !elem.IsNil():
+		return enc.AddObject(key, redactedMap{elem, path})
+	default:
+		return enc.AddReflected(key, valueInterface(v))
+	}
+}
+
+// isNil reports whether v is nil, which encodes as null.
+func isNil(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Invalid:
+		return true
+	case reflect.Ptr, reflect.Interface, reflect.Slice, reflect.Map:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+// isBytes reports whether v is a byte slice, which encodes in base64.
+func isBytes(v reflect.Value) bool {
+	return v.Kind() == reflect.Slice && !v.IsNil() && v.Type().Elem().Kind() == reflect.Uint8
+}
+
+// indirect follows the pointers and interfaces of v.
+func indirect(v reflect.Value) reflect.Value {
+	for (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && !v.IsNil() {
+		v = v.Elem()
+	}
+	return v
+}
+
+func valueInterface(v reflect.Value) interface{} {
+	if !v.IsValid() {
+		return nil
+	}
+	return v.Interface()
+}
@@ -0,0 +1,228 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap/zapcore"
+)
+
+type redactedUser struct {
+	Name     string `json:"name"`
+	Password string `json:"-" log:"-"`
+	Email    string `json:"email" log:"mask"`
+}
+
+type redactedNode struct {
+	Name     string                   `json:"name"`
+	Secret   string                   `json:"secret" log:"mask"`
+	Next     *redactedNode            `json:"next"`
+	Children map[string]*redactedNode `json:"children"`
+}
+
+func logRedacted(t *testing.T, v interface{}) map[string]interface{} {
+	t.Helper()
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf})
+	l.InfoFields("redacted", Redacted("v", v))
+	return decodeLines(t, &buf)[0]
+}
+
+func TestRedacted(t *testing.T) {
+	user := redactedUser{Name: "gopher", Password: "hunter2", Email: "gopher@example.com"}
+	line := logRedacted(t, struct {
+		User  *redactedUser            `json:"user"`
+		Users []redactedUser           `json:"users"`
+		ByID  map[int]redactedUser     `json:"by_id"`
+		Any   map[string]interface{}   `json:"any"`
+		Ptrs  map[string]*redactedUser `json:"ptrs"`
+	}{
+		User:  &user,
+		Users: []redactedUser{user},
+		ByID:  map[int]redactedUser{1: user},
+		Any:   map[string]interface{}{"user": user, "n": 1},
+		Ptrs:  map[string]*redactedUser{"a": &user, "b": &user},
+	})
+
+	got := line["v"].(map[string]interface{})
+	for _, u := range []interface{}{
+		got["user"],
+		got["users"].([]interface{})[0],
+		got["by_id"].(map[string]interface{})["1"],
+		got["any"].(map[string]interface{})["user"],
+		got["ptrs"].(map[string]interface{})["a"],
+		got["ptrs"].(map[string]interface{})["b"],
+	} {
+		u := u.(map[string]interface{})
+		if u["name"] != "gopher" || u["email"] != redactedMask || u["Password"] != nil || len(u) != 2 {
+			t.Errorf("user = %v, want the name and the masked email", u)
+		}
+	}
+	if n := got["any"].(map[string]interface{})["n"]; n != 1.0 {
+		t.Errorf("any.n = %v, want 1", n)
+	}
+}
+
+func TestRedactedMap(t *testing.T) {
+	user := redactedUser{Name: "gopher", Email: "gopher@example.com"}
+	line := logRedacted(t, map[string]redactedUser{"admin": user})
+
+	u := line["v"].(map[string]interface{})["admin"].(map[string]interface{})
+	if u["email"] != redactedMask {
+		t.Errorf("email = %v, want it masked", u["email"])
+	}
+}
+
+func TestRedactedCycle(t *testing.T) {
+	node := &redactedNode{Name: "a", Secret: "s"}
+	node.Next = node
+	line := logRedacted(t, node)
+	if msg, _ := line["vError"].(string); !strings.Contains(msg, "cyclic") {
+		t.Errorf("vError = %v, want the cycle reported", line["vError"])
+	}
+
+	node = &redactedNode{Name: "a", Secret: "s"}
+	node.Children = map[string]*redactedNode{"self": node}
+	line = logRedacted(t, []*redactedNode{node})
+	if msg, _ := line["vError"].(string); !strings.Contains(msg, "cyclic") {
+		t.Errorf("vError = %v, want the cycle reported", line["vError"])
+	}
+
+	m := map[string]interface{}{}
+	m["self"] = m
+	line = logRedacted(t, m)
+	if msg, _ := line["vError"].(string); !strings.Contains(msg, "cyclic") {
+		t.Errorf("vError = %v, want the cycle reported", line["vError"])
+	}
+}
+
+func TestRedactedShared(t *testing.T) {
+	// a value reached twice without a cycle is encoded twice.
+	leaf := &redactedNode{Name: "leaf", Secret: "s"}
+	root := &redactedNode{Name: "root", Next: leaf, Children: map[string]*redactedNode{"leaf": leaf}}
+	line := logRedacted(t, root)
+
+	if _, ok := line["vError"]; ok {
+		t.Fatalf("vError = %v, want none", line["vError"])
+	}
+	v := line["v"].(map[string]interface{})
+	for _, n := range []interface{}{v["next"], v["children"].(map[string]interface{})["leaf"]} {
+		n := n.(map[string]interface{})
+		if n["name"] != "leaf" || n["secret"] != redactedMask {
+			t.Errorf("leaf = %v, want the name and the masked secret", n)
+		}
+	}
+}
+
+type redactedBase struct {
+	ID     int    `json:"id"`
+	Token  string `json:"token" log:"mask"`
+	Shadow string `json:"name"`
+}
+
+type redactedAudit struct {
+	By string `json:"by"`
+}
+
+type redactedEmbedding struct {
+	redactedBase
+	*redactedAudit
+	Name string `json:"name"`
+}
+
+type redactedJSON struct {
+	Secret string `log:"mask"`
+}
+
+func (redactedJSON) MarshalJSON() ([]byte, error) { return []byte(`"custom"`), nil }
+
+type redactedObject struct {
+	Secret string `log:"mask"`
+}
+
+func (redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("custom", "object")
+	return nil
+}
+
+// encodeJSON returns v encoded by encoding/json and decoded again, which is
+// what Redacted must match for the values without log tags.
+func encodeJSON(t *testing.T, v interface{}) interface{} {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(b, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	return decoded
+}
+
+func TestRedactedBytes(t *testing.T) {
+	v := struct {
+		Data  []byte   `json:"data"`
+		Nil   []byte   `json:"nil"`
+		List  [][]byte `json:"list"`
+		Array [2]byte  `json:"array"`
+	}{Data: []byte("hello"), List: [][]byte{[]byte("a"), nil}, Array: [2]byte{1, 2}}
+
+	if got, want := logRedacted(t, v)["v"], encodeJSON(t, v); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got, want := logRedacted(t, []byte("hello"))["v"], encodeJSON(t, []byte("hello")); got != want {
+		t.Errorf("top-level bytes = %v, want %v", got, want)
+	}
+}
+
+func TestRedactedEmbedded(t *testing.T) {
+	v := redactedEmbedding{
+		redactedBase: redactedBase{ID: 1, Token: "secret", Shadow: "shadowed"},
+		Name:         "outer",
+	}
+	got := logRedacted(t, v)["v"].(map[string]interface{})
+	want := map[string]interface{}{"id": 1.0, "token": redactedMask, "name": "outer"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+
+	// the fields promoted from a non-nil pointer are encoded as well.
+	v.redactedAudit = &redactedAudit{By: "admin"}
+	got = logRedacted(t, v)["v"].(map[string]interface{})
+	if got["by"] != "admin" {
+		t.Errorf("by = %v, want admin", got["by"])
+	}
+	v.Token = ""
+	if want := encodeJSON(t, v).(map[string]interface{}); len(got) != len(want) {
+		t.Errorf("got the keys %v, want the ones of encoding/json %v", got, want)
+	}
+}
+
+func TestRedactedMarshalers(t *testing.T) {
+	if got := logRedacted(t, redactedJSON{Secret: "s"})["v"]; got != "custom" {
+		t.Errorf("json.Marshaler = %v, want custom", got)
+	}
+	if got := logRedacted(t, &redactedJSON{Secret: "s"})["v"]; got != "custom" {
+		t.Errorf("pointer to json.Marshaler = %v, want custom", got)
+	}
+	got := logRedacted(t, redactedObject{Secret: "s"})["v"]
+	if want := map[string]interface{}{"custom": "object"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("zapcore.ObjectMarshaler = %v, want %v", got, want)
+	}
+}
+
+func TestRedactedNilSlice(t *testing.T) {
+	var users []redactedUser
+	line := logRedacted(t, users)
+	if v, ok := line["v"]; !ok || v != nil {
+		t.Errorf("v = %v, want null", line["v"])
+	}
+	line = logRedacted(t, []redactedUser{})
+	if v, ok := line["v"].([]interface{}); !ok || len(v) != 0 {
+		t.Errorf("v = %v, want []", line["v"])
+	}
+}
@@ -0,0 +1,49 @@
+package logger
+
+import "sync"
+
+// registry holds the loggers which have resources to release, so that CloseAll
+// can close them. A logger is registered by NewWithError and removed by Close,
+// the loggers writing only to writers, stdout or stderr are never registered.
+// A logger which is never closed is retained, along with its open files, as
+// it would be without the registry.
+var registry = struct {
+	sync.Mutex
+	loggers map[*resources]Logger
+}{loggers: make(map[*resources]Logger)}
+
+func register(l Logger) {
+	if len(l.res.closers) == 0 {
+		return
+	}
+	registry.Lock()
+	registry.loggers[l.res] = l
+	registry.Unlock()
+}
+
+func unregister(res *resources) {
+	registry.Lock()
+	delete(registry.loggers, res)
+	registry.Unlock()
+}
+
+// CloseAll closes all the loggers created by the package which haven't been
+// closed yet, like the ones writing to log files, and returns the first error.
+// It is meant to be called on shutdown, the closed loggers reopen their files
+// if they write again.
+func CloseAll() error {
+	registry.Lock()
+	loggers := make([]Logger, 0, len(registry.loggers))
+	for _, l := range registry.loggers {
+		loggers = append(loggers, l)
+	}
+	registry.Unlock()
+
+	var err error
+	for _, l := range loggers {
+		if cerr := l.Close(); cerr != nil && err == nil {
+			err = cerr
+		}
+	}
+	return err
+}
@@ -0,0 +1,47 @@
+package logger
+
+import (
+	"bytes"
+	"sync"
+)
+
+// ringBuffer retains the most recent entries written to it, every write is
+// taken as one entry since the cores write an entry at a time.
+type ringBuffer struct {
+	mu      sync.Mutex
+	entries []string
+	next    int
+	full    bool
+}
+
+func newRingBuffer(size int) *ringBuffer {
+	return &ringBuffer{entries: make([]string, size)}
+}
+
+// Write saves p without the trailing newline, overwriting the oldest entry if
+// the buffer is full.
+func (r *ringBuffer) Write(p []byte) (int, error) {
+	entry := string(bytes.TrimSuffix(p, []byte("\n")))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = entry
+	r.next++
+	if r.next == len(r.entries) {
+		r.next, r.full = 0, true
+	}
+	return len(p), nil
+}
+
+// recent returns a copy of the entries, the oldest first.
+func (r *ringBuffer) recent() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !r.full {
+		return append([]string(nil), r.entries[:r.next]...)
+	}
+	entries := make([]string, 0, len(r.entries))
+	entries = append(entries, r.entries[r.next:]...)
+	return append(entries, r.entries[:r.next]...)
+}
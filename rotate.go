@@ -0,0 +1,340 @@
+package logger
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// ErrNoLogFile is returned by Rotate if the logger doesn't write to any log
+// file.
+var ErrNoLogFile = errors.New("logger: no log file to rotate")
+
+const (
+	megabyte       = 1024 * 1024
+	defaultMaxSize = 100
+
+	// backupTimeFormat is the layout of the timestamp in the names of the
+	// backups created by lumberjack.
+	backupTimeFormat = "2006-01-02T15-04-05.000"
+)
+
+// rotatingFile wraps the lumberjack logger to find out when it rotates, since
+// lumberjack does not tell. It repeats the size check of lumberjack before
+// every write to tell whether the write is going to rotate the file. It also
+// rotates the file by time, which lumberjack doesn't support.
+type rotatingFile struct {
+	*lumberjack.Logger
+	onRotate func(oldPath string)
+
+	// backupPattern is the pattern the backups are renamed to, the time in
+	// the names is in UTC if utc is true.
+	backupPattern string
+	utc           bool
+
+	// nextRotation returns the time when the file written at t is due to be
+	// rotated, it is nil if the file is not rotated by time.
+	nextRotation func(t time.Time) time.Time
+
+	// array writes the entries as the elements of a JSON array.
+	array bool
+
+	mu     sync.Mutex
+	opened bool
+	size   int64
+	next   time.Time
+}
+
+func newRotatingFile(file *lumberjack.Logger, opt Options, array bool) *rotatingFile {
+	return &rotatingFile{
+		Logger:        file,
+		onRotate:      opt.OnRotate,
+		backupPattern: opt.BackupNamePattern,
+		utc:           opt.UTC,
+		nextRotation:  opt.nextRotation(),
+		array:         array,
+	}
+}
+
+// Write writes p to the file and fires onRotate if the file was rotated.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	if f.onRotate == nil && f.backupPattern == "" && f.nextRotation == nil && !f.array {
+		return f.Logger.Write(p)
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.nextRotation != nil {
+		if err := f.rotateByTime(time.Now()); err != nil {
+			return 0, err
+		}
+	}
+
+	// lumberjack opens the file lazily and rotates it right away if the write
+	// doesn't fit in, even exactly.
+	var opening bool
+	if !f.opened {
+		f.size = 0
+		if f.array {
+			if err := f.reopenArray(); err != nil {
+				return 0, err
+			}
+		}
+		if fi, err := os.Stat(f.Filename); err == nil {
+			f.size, opening = fi.Size(), true
+		}
+	}
+
+	data := p
+	if f.array {
+		data = arrayElements(p, f.size == 0)
+	}
+	writeLen := int64(len(data))
+	rotate := f.size+writeLen > f.max() || opening && f.size+writeLen >= f.max()
+	if rotate && f.array {
+		// the array has to be closed before lumberjack moves the file aside,
+		// so the file is rotated here rather than by lumberjack.
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+		data, rotate = arrayElements(p, true), false
+	}
+
+	n, err := f.Logger.Write(data)
+	if err != nil {
+		// the state of lumberjack is unknown, start over on the next write.
+		f.opened = false
+		return 0, err
+	}
+
+	f.opened = true
+	if rotate {
+		f.size = 0
+		f.rotated()
+	}
+	f.size += int64(n)
+	return len(p), nil
+}
+
+// rotateByTime rotates the file if it is due. The check is done on writes, so
+// a file left idle across the boundary is rotated by the next write, and so is
+// a file left by the previous run of the program.
+func (f *rotatingFile) rotateByTime(now time.Time) error {
+	if f.next.IsZero() {
+		f.next = f.nextRotation(now)
+		fi, err := os.Stat(f.Filename)
+		if err != nil || fi.Size() == 0 || now.Before(f.nextRotation(fi.ModTime())) {
+			return nil
+		}
+	} else if now.Before(f.next) {
+		return nil
+	}
+
+	f.next = f.nextRotation(now)
+	return f.rotate()
+}
+
+// Rotate rotates the file right away and fires onRotate.
+func (f *rotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.rotate()
+}
+
+func (f *rotatingFile) rotate() error {
+	if f.array {
+		if err := f.closeArray(); err != nil {
+			return err
+		}
+	}
+
+	// lumberjack only makes a backup if the file exists.
+	_, statErr := os.Stat(f.Filename)
+	if err := f.Logger.Rotate(); err != nil {
+		f.opened = false
+		return err
+	}
+
+	f.opened, f.size = true, 0
+	if statErr == nil {
+		f.rotated()
+	}
+	return nil
+}
+
+// Sync is a no-op, lumberjack doesn't buffer anything.
+func (f *rotatingFile) Sync() error {
+	return nil
+}
+
+// Close closes the file, it is reopened by the next write.
+func (f *rotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.opened = false
+	var err error
+	if f.array {
+		err = f.closeArray()
+	}
+	if cerr := f.Logger.Close(); cerr != nil && err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (f *rotatingFile) max() int64 {
+	if f.MaxSize == 0 {
+		return defaultMaxSize * megabyte
+	}
+	return int64(f.MaxSize) * megabyte
+}
+
+// rotated renames the newest backup after the pattern if there is one, and
+// fires onRotate with it in a new goroutine.
+func (f *rotatingFile) rotated() {
+	if f.onRotate == nil && f.backupPattern == "" {
+		return
+	}
+
+	backup := f.latestBackup()
+	if backup == "" {
+		return
+	}
+	if f.backupPattern != "" {
+		backup = f.renameBackup(backup)
+	}
+	if f.onRotate != nil {
+		go f.onRotate(backup)
+	}
+}
+
+// renameBackup renames the backup after the pattern and returns its new path,
+// or the old one if it can't be renamed.
+func (f *rotatingFile) renameBackup(backup string) string {
+	fi, err := os.Stat(backup)
+	if err != nil {
+		return backup
+	}
+	t := fi.ModTime()
+	if f.utc {
+		t = t.UTC()
+	}
+
+	name := filepath.Join(filepath.Dir(f.Filename), expandBackupPattern(f.backupPattern, filepath.Base(f.Filename), t))
+	path := name
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(path); os.IsNotExist(err) {
+			break
+		}
+		path = name + "." + strconv.Itoa(i)
+	}
+	if err := os.Rename(backup, path); err != nil {
+		return backup
+	}
+	return path
+}
+
+// expandBackupPattern replaces the tokens of the pattern, filename is the name
+// of the log file and t is the time of the last write to the backup.
+func expandBackupPattern(pattern, filename string, t time.Time) string {
+	ext := filepath.Ext(filename)
+	var b strings.Builder
+	for i := 0; i < len(pattern); i++ {
+		if pattern[i] != '%' || i == len(pattern)-1 {
+			b.WriteByte(pattern[i])
+			continue
+		}
+		i++
+		switch pattern[i] {
+		case 'n':
+			b.WriteString(strings.TrimSuffix(filename, ext))
+		case 'e':
+			b.WriteString(ext)
+		case 'Y':
+			b.WriteString(t.Format("2006"))
+		case 'm':
+			b.WriteString(t.Format("01"))
+		case 'd':
+			b.WriteString(t.Format("02"))
+		case 'H':
+			b.WriteString(t.Format("15"))
+		case 'M':
+			b.WriteString(t.Format("04"))
+		case 'S':
+			b.WriteString(t.Format("05"))
+		case '%':
+			b.WriteByte('%')
+		default:
+			b.WriteByte('%')
+			b.WriteByte(pattern[i])
+		}
+	}
+	return b.String()
+}
+
+// latestBackup returns the path of the newest backup of the file, which may
+// have been compressed already.
+func (f *rotatingFile) latestBackup() string {
+	dir := filepath.Dir(f.Filename)
+	filename := filepath.Base(f.Filename)
+	ext := filepath.Ext(filename)
+	prefix := filename[:len(filename)-len(ext)] + "-"
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+
+	var latest, latestTime string
+	for _, entry := range entries {
+		name := entry.Name()
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, ".gz"), ext)
+		if entry.IsDir() || !strings.HasPrefix(ts, prefix) {
+			continue
+		}
+		ts = ts[len(prefix):]
+		if len(ts) != len(backupTimeFormat) {
+			continue
+		}
+		// the timestamps have a fixed width, so they sort lexically.
+		if ts > latestTime {
+			latest, latestTime = name, ts
+		}
+	}
+
+	if latest == "" {
+		return ""
+	}
+	return filepath.Join(dir, latest)
+}
+
+// dailyRotation returns the next midnight after t, in UTC if utc is true or in
+// the local time zone otherwise.
+func dailyRotation(utc bool) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		if utc {
+			t = t.UTC()
+		} else {
+			t = t.Local()
+		}
+		year, month, day := t.Date()
+		return time.Date(year, month, day+1, 0, 0, 0, 0, t.Location())
+	}
+}
+
+// intervalRotation returns the next multiple of interval after t, counted
+// from the zero time.
+func intervalRotation(interval time.Duration) func(time.Time) time.Time {
+	return func(t time.Time) time.Time {
+		return t.Truncate(interval).Add(interval)
+	}
+}
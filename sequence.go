@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// seqCore numbers the entries written by the wrapped core with the "seq"
+// field. The counter is shared by the loggers derived by With and Named, so
+// the numbers are unique across all of them.
+type seqCore struct {
+	zapcore.Core
+	seq         *uint64
+	errorOutput zapcore.WriteSyncer
+}
+
+func newSeqCore(core zapcore.Core, errorOutput zapcore.WriteSyncer) *seqCore {
+	return &seqCore{Core: core, seq: new(uint64), errorOutput: errorOutput}
+}
+
+// With adds fields to a copy of the core, the copy shares the counter.
+func (c *seqCore) With(fields []zapcore.Field) zapcore.Core {
+	return &seqCore{Core: c.Core.With(fields), seq: c.seq, errorOutput: c.errorOutput}
+}
+
+// Check adds the core if the entry is enabled.
+func (c *seqCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write numbers the entry and writes it. The wrapped core is checked again
+// since it may be a tee whose outputs have different levels, the write errors
+// are reported to the error output of the logger.
+func (c *seqCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ce := c.Core.Check(ent, nil)
+	if ce == nil {
+		return nil
+	}
+	ce.ErrorOutput = c.errorOutput
+	seq := atomic.AddUint64(c.seq, 1)
+	ce.Write(append(fields[:len(fields):len(fields)], zap.Uint64("seq", seq))...)
+	return nil
+}
@@ -0,0 +1,70 @@
+//go:build !js
+// +build !js
+
+package logger
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// handleSIGHUP rotates the log files of l and reopens its File on every
+// SIGHUP, or only syncs it if there is neither of them, until the returned
+// closer is closed. Every logger is notified on its own channel, so that
+// stopping one leaves the others notified. The failures are reported to
+// errorOutput.
+func handleSIGHUP(l Logger, errorOutput zapcore.WriteSyncer) closerFunc {
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGHUP)
+
+	stop, done := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(done)
+
+		for {
+			select {
+			case <-sig:
+				if err := l.reload(); err != nil {
+					fmt.Fprintf(errorOutput, "logger: failed to handle SIGHUP: %v\n", err)
+					errorOutput.Sync()
+				}
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	// stopping is idempotent, so that a closed logger can be closed again.
+	var once sync.Once
+	return func() error {
+		once.Do(func() {
+			signal.Stop(sig)
+			close(stop)
+		})
+		<-done
+		return nil
+	}
+}
+
+// reload rotates the log files and reopens the File of the logger.
+func (l Logger) reload() error {
+	if len(l.res.files) == 0 && len(l.res.opened) == 0 {
+		return l.sugared.Sync()
+	}
+
+	var err error
+	if len(l.res.files) > 0 {
+		err = l.Rotate()
+	}
+	if len(l.res.opened) > 0 {
+		if rerr := l.ReopenFile(); rerr != nil && err == nil {
+			err = rerr
+		}
+	}
+	return err
+}
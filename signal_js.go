@@ -0,0 +1,8 @@
+package logger
+
+import "go.uber.org/zap/zapcore"
+
+// handleSIGHUP does nothing, since there are no signals on js/wasm.
+func handleSIGHUP(Logger, zapcore.WriteSyncer) closerFunc {
+	return func() error { return nil }
+}
@@ -0,0 +1,205 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// SlogHandler returns a slog.Handler which writes records through the logger,
+// so that an *slog.Logger shares its outputs, encoding and level.
+func (l Logger) SlogHandler() slog.Handler {
+	return &slogHandler{logger: l.sugared.Desugar()}
+}
+
+type slogHandler struct {
+	logger *zap.Logger
+
+	// groups are opened by WithGroup but not turned into namespaces yet since
+	// slog omits groups without any attributes.
+	groups []string
+}
+
+// Enabled reports whether the handler handles records at the given level.
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(slogLevel(level))
+}
+
+// Handle writes the record with the time and the caller taken from it, and
+// the stacktrace starting from the caller.
+func (h *slogHandler) Handle(_ context.Context, r slog.Record) error {
+	ce := h.logger.Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.EntryCaller{
+			Defined:  true,
+			PC:       frame.PC,
+			File:     frame.File,
+			Line:     frame.Line,
+			Function: frame.Function,
+		}
+	}
+	// the stacktrace taken by zap starts in slog, it is taken again from the
+	// frame of the caller.
+	if ce.Stack != "" && r.PC != 0 {
+		if stack := slogStack(r.PC); stack != "" {
+			ce.Stack = stack
+		}
+	}
+
+	var fields []zap.Field
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, a)
+		return true
+	})
+	if len(fields) > 0 {
+		fields = append(h.namespaces(), fields...)
+	}
+	ce.Write(fields...)
+	return nil
+}
+
+// WithAttrs returns a handler whose records carry the given attributes.
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zap.Field
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, a)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	return &slogHandler{logger: h.logger.With(append(h.namespaces(), fields...)...)}
+}
+
+// WithGroup returns a handler which nests the subsequent attributes under name.
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := append(h.groups[:len(h.groups):len(h.groups)], name)
+	return &slogHandler{logger: h.logger, groups: groups}
+}
+
+func (h *slogHandler) namespaces() []zap.Field {
+	fields := make([]zap.Field, 0, len(h.groups))
+	for _, g := range h.groups {
+		fields = append(fields, zap.Namespace(g))
+	}
+	return fields
+}
+
+// slogStack formats the stack of the goroutine from the frame of pc on, as
+// zap formats the stacktraces. It returns an empty string if pc is not on the
+// stack, like for a record handled by another goroutine.
+func slogStack(pc uintptr) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	for n == len(pcs) {
+		pcs = make([]uintptr, 2*len(pcs))
+		n = runtime.Callers(2, pcs)
+	}
+	pcs = pcs[:n]
+
+	for i, p := range pcs {
+		if p != pc {
+			continue
+		}
+		var b strings.Builder
+		frames := runtime.CallersFrames(pcs[i:])
+		// the last frame is runtime.main or runtime.goexit, which zap leaves
+		// out as well.
+		for frame, more := frames.Next(); more; frame, more = frames.Next() {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			b.WriteString(frame.Function)
+			b.WriteString("\n\t")
+			b.WriteString(frame.File)
+			b.WriteByte(':')
+			b.WriteString(strconv.Itoa(frame.Line))
+		}
+		return b.String()
+	}
+	return ""
+}
+
+// slogLevel maps the slog level to the closest level of this package.
+func slogLevel(level slog.Level) zapcore.Level {
+	switch {
+	case level < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case level < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case level < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+// appendSlogAttr translates the attribute into zap fields. Empty attributes
+// are dropped and the attributes of a group without a key are inlined.
+func appendSlogAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, a.Value.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, a.Value.Duration()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, a.Value.Float64()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, a.Value.Int64()))
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, a.Value.String()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, a.Value.Time()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, a.Value.Uint64()))
+	case slog.KindGroup:
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, attr := range attrs {
+				fields = appendSlogAttr(fields, attr)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	default:
+		return append(fields, zap.Any(a.Key, a.Value.Any()))
+	}
+}
+
+// slogGroup encodes the attributes of a group as a nested object.
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, a := range g {
+		for _, f := range appendSlogAttr(nil, a) {
+			f.AddTo(enc)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+//go:build go1.21
+// +build go1.21
+
+package logger
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSlogHandler(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, DisableTime: true, Level: DebugLevel})
+	s := slog.New(l.SlogHandler())
+
+	s.Debug("debug")
+	s.Info("attrs", "str", "v", "int", 1, slog.Bool("bool", true))
+	s.Warn("warn")
+	s.Error("error")
+	s.With("a", 1).WithGroup("req").With("method", "GET").WithGroup("empty").Info("groups",
+		slog.Group("user", "id", 7), slog.Group("none"))
+	s.WithGroup("unused").Info("no attrs")
+
+	entries := decodeLines(t, &buf)
+	want := []map[string]interface{}{
+		{"level": "DEBUG", "msg": "debug"},
+		{"level": "INFO", "msg": "attrs", "str": "v", "int": 1.0, "bool": true},
+		{"level": "WARN", "msg": "warn"},
+		{"level": "ERROR", "msg": "error"},
+		{"level": "INFO", "msg": "groups", "a": 1.0, "req": map[string]interface{}{
+			"method": "GET",
+			"empty":  map[string]interface{}{"user": map[string]interface{}{"id": 7.0}},
+		}},
+		{"level": "INFO", "msg": "no attrs"},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("got %d entries, want %d:\n%s", len(entries), len(want), buf.String())
+	}
+	for i, entry := range entries {
+		caller, _ := entry["caller"].(string)
+		if !strings.Contains(caller, "slog_test.go:") {
+			t.Errorf("entry %d: caller = %q, want slog_test.go", i, caller)
+		}
+		delete(entry, "caller")
+		if !reflect.DeepEqual(entry, want[i]) {
+			t.Errorf("entry %d:\ngot  %v\nwant %v", i, entry, want[i])
+		}
+	}
+}
+
+func TestSlogHandlerLevel(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, Level: WarnLevel})
+	s := slog.New(l.SlogHandler())
+
+	if s.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("info is enabled at WarnLevel")
+	}
+	s.Info("dropped")
+	s.Log(context.Background(), slog.LevelError+4, "above error")
+
+	entries := decodeLines(t, &buf)
+	if len(entries) != 1 || entries[0]["level"] != "ERROR" {
+		t.Fatalf("got %v, want a single ERROR entry", entries)
+	}
+}
+
+func TestSlogHandlerStacktrace(t *testing.T) {
+	var buf bytes.Buffer
+	lvl := ErrorLevel
+	l := New(Options{Writer: &buf, StacktraceLevel: &lvl})
+	slog.New(l.SlogHandler()).Error("failed")
+
+	stack, _ := decodeLines(t, &buf)[0]["stacktrace"].(string)
+	first := strings.SplitN(stack, "\n", 2)[0]
+	if want := "github.com/chenjiandongx/logger.TestSlogHandlerStacktrace"; first != want {
+		t.Errorf("first frame = %q, want %q:\n%s", first, want, stack)
+	}
+}
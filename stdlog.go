@@ -0,0 +1,159 @@
+package logger
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// StdLogWriter returns an io.Writer which logs every write as a single entry
+// at the given level, for example as the output of log.SetOutput. The caller
+// annotation is suppressed since it can not be traced back reliably.
+func (l Logger) StdLogWriter(level Level) io.Writer {
+	return stdLogWriter{
+		logger: l.sugared.Desugar().WithOptions(zap.WithCaller(false)),
+		level:  zapcore.Level(level),
+	}
+}
+
+// AsStdLog returns a *log.Logger which logs at the given level through the
+// logger, the caller annotation points at the caller of the *log.Logger.
+func (l Logger) AsStdLog(level Level) *log.Logger {
+	// skip log.(*Logger).Print* and log.(*Logger).output in addition to Write.
+	w := stdLogWriter{
+		logger: l.sugared.Desugar().WithOptions(zap.AddCallerSkip(2)),
+		level:  zapcore.Level(level),
+	}
+	return log.New(w, "", 0)
+}
+
+type stdLogWriter struct {
+	logger *zap.Logger
+	level  zapcore.Level
+}
+
+// Write logs p without the trailing newline appended by the log package.
+func (w stdLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte("\n")))
+	if ce := w.logger.Check(w.level, msg); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
+
+// LevelPrefix maps the lines starting with Prefix to Level for LevelWriter.
+type LevelPrefix struct {
+	Prefix string
+	Level  Level
+}
+
+// defaultLevelPrefixes are the prefixes used by LevelWriter if none is given,
+// WARN matches WARNING as well.
+var defaultLevelPrefixes = []LevelPrefix{
+	{Prefix: "DEBUG", Level: DebugLevel},
+	{Prefix: "INFO", Level: InfoLevel},
+	{Prefix: "WARN", Level: WarnLevel},
+	{Prefix: "ERROR", Level: ErrorLevel},
+}
+
+// LevelWriter returns an io.WriteCloser which logs every line written to it as
+// an entry, like the output of a subprocess. The level of a line is the one of
+// the first prefix it starts with, or defaultLevel if it matches none. The
+// prefixes default to DEBUG, INFO, WARN and ERROR, and the ones at PanicLevel
+// or above panic and exit as the logging methods do:
+//
+//	cmd.Stdout = l.LevelWriter(logger.InfoLevel)
+//	cmd.Stderr = l.LevelWriter(logger.ErrorLevel, logger.LevelPrefix{Prefix: "W ", Level: logger.WarnLevel})
+//
+// A line may span several writes, the unterminated rest is held until the
+// newline arrives, and is logged by Close. The lines longer than 64 KiB are
+// logged in pieces, without splitting a rune. Empty lines are skipped. The
+// caller annotation is suppressed like StdLogWriter.
+func (l Logger) LevelWriter(defaultLevel Level, prefixes ...LevelPrefix) io.WriteCloser {
+	if len(prefixes) == 0 {
+		prefixes = defaultLevelPrefixes
+	}
+	return &levelWriter{
+		logger:       l.sugared.Desugar().WithOptions(zap.WithCaller(false)),
+		defaultLevel: zapcore.Level(defaultLevel),
+		prefixes:     prefixes,
+		max:          levelWriterMaxLine,
+	}
+}
+
+// levelWriterMaxLine is the maximum length of the line logged by LevelWriter.
+const levelWriterMaxLine = 64 << 10
+
+type levelWriter struct {
+	logger       *zap.Logger
+	defaultLevel zapcore.Level
+	prefixes     []LevelPrefix
+	max          int
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+// Write logs the lines completed by p and holds the unterminated rest.
+func (w *levelWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf = append(w.buf, p...)
+	start := 0
+	for {
+		rest := w.buf[start:]
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 && i <= w.max {
+			w.log(rest[:i])
+			start += i + 1
+			continue
+		}
+		if len(rest) <= w.max {
+			break
+		}
+		// the line is too long, its head is logged on its own.
+		n := runeBoundary(rest, w.max)
+		if n == 0 {
+			n = w.max
+		}
+		w.log(rest[:n])
+		start += n
+	}
+	// move the rest to the front, so that the buffer is reused rather than
+	// growing forever.
+	w.buf = w.buf[:copy(w.buf, w.buf[start:])]
+	return len(p), nil
+}
+
+// Close logs the unterminated rest.
+func (w *levelWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.log(w.buf)
+	w.buf = nil
+	return nil
+}
+
+// log logs the line at the level picked by its prefix.
+func (w *levelWriter) log(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	if len(line) == 0 {
+		return
+	}
+
+	level := w.defaultLevel
+	for _, prefix := range w.prefixes {
+		if bytes.HasPrefix(line, []byte(prefix.Prefix)) {
+			level = zapcore.Level(prefix.Level)
+			break
+		}
+	}
+	if ce := w.logger.Check(level, string(line)); ce != nil {
+		ce.Write()
+	}
+}
@@ -0,0 +1,68 @@
+package logger
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func levelWriterMessages(t *testing.T, max int, writes ...string) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	w := New(Options{Writer: &buf}).LevelWriter(InfoLevel).(*levelWriter)
+	w.max = max
+	for _, p := range writes {
+		w.Write([]byte(p))
+		if len(w.buf) > max {
+			t.Errorf("%d bytes held after writing %q, want %d at most", len(w.buf), p, max)
+		}
+	}
+	w.Close()
+
+	var msgs []string
+	if buf.Len() == 0 {
+		return msgs
+	}
+	for _, line := range decodeLines(t, &buf) {
+		msgs = append(msgs, line["msg"].(string))
+	}
+	return msgs
+}
+
+func TestLevelWriterLines(t *testing.T) {
+	got := levelWriterMessages(t, 64, "first\nsec", "ond\r\n\nthi", "rd")
+	if want := "first|second|third"; strings.Join(got, "|") != want {
+		t.Errorf("got %q, want %s", got, want)
+	}
+}
+
+func TestLevelWriterLongLine(t *testing.T) {
+	for _, tt := range []struct {
+		writes []string
+		want   string
+	}{
+		// the pending line is flushed once it is too long.
+		{[]string{"0123", "4567", "89ab", "cd\n"}, "01234567|89abcd"},
+		{[]string{"0123456789abcdefgh"}, "01234567|89abcdef|gh"},
+		{[]string{"0123456789\nab\n"}, "01234567|89|ab"},
+		// 日 is 3 bytes, [6:9].
+		{[]string{"abcdef日本\n"}, "abcdef|日本"},
+	} {
+		got := levelWriterMessages(t, 8, tt.writes...)
+		if strings.Join(got, "|") != tt.want {
+			t.Errorf("%q: got %q, want %s", tt.writes, got, tt.want)
+		}
+	}
+}
+
+func TestLevelWriterReusesBuffer(t *testing.T) {
+	w := New(Options{Writer: &bytes.Buffer{}}).LevelWriter(InfoLevel).(*levelWriter)
+	w.Write([]byte("partial\npartial"))
+	held := &w.buf[:1][0]
+	for i := 0; i < 100; i++ {
+		w.Write([]byte("\npartial"))
+	}
+	if &w.buf[:1][0] != held {
+		t.Error("the buffer is reallocated, want it reused")
+	}
+}
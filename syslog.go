@@ -0,0 +1,308 @@
+package logger
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	syslogTimeFormat   = "2006-01-02T15:04:05.000000Z07:00"
+	syslogMaxAppName   = 48
+	syslogQueueSize    = 1024
+	syslogDialTimeout  = 5 * time.Second
+	syslogWriteTimeout = 5 * time.Second
+	syslogMinBackoff   = 100 * time.Millisecond
+	syslogMaxBackoff   = 30 * time.Second
+)
+
+// SyslogConfig is the option set for sending logs to a remote syslog server,
+// such as rsyslog or Fluentd. Logs are formatted as RFC 5424 messages whose
+// MSG part is the JSON encoded entry, messages sent over TCP are framed by
+// octet counting as described in RFC 6587.
+//
+// Messages are sent by a background goroutine which reconnects with
+// exponential backoff when the connection is lost, so logging never blocks on
+// the network. Messages are queued in the meantime and dropped once the queue
+// is full. The delivery is best effort, a message written right before the
+// connection breaks may be lost.
+type SyslogConfig struct {
+	// Network is either "tcp" or "udp".
+	Network string `json:"network,omitempty" yaml:"network,omitempty"`
+
+	// Address is the address of the syslog server, like "localhost:514".
+	Address string `json:"address,omitempty" yaml:"address,omitempty"`
+
+	// Facility is the syslog facility code defined by RFC 5424, like 16 for
+	// local0. Zero stands for user-level messages (1) rather than kernel
+	// messages, which are not sent by applications.
+	Facility int `json:"facility,omitempty" yaml:"facility,omitempty"`
+
+	// Tag is the APP-NAME of the messages, it defaults to the name of the
+	// program.
+	Tag string `json:"tag,omitempty" yaml:"tag,omitempty"`
+
+	// QueueSize is the maximum number of messages waiting to be sent, it
+	// defaults to 1024.
+	QueueSize int `json:"queue_size,omitempty" yaml:"queue_size,omitempty"`
+
+	// DropOldest drops the oldest queued message to make room for a new one
+	// when the queue is full, instead of dropping the new one.
+	DropOldest bool `json:"drop_oldest,omitempty" yaml:"drop_oldest,omitempty"`
+}
+
+// syslogCore encodes entries as syslog messages and hands them to the sender.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc      zapcore.Encoder
+	sender   *syslogSender
+	facility int
+	header   string // the HOSTNAME, APP-NAME, PROCID, MSGID and STRUCTURED-DATA parts.
+	octet    bool
+}
+
+// validate checks the network, the address and the facility.
+func (cfg SyslogConfig) validate() error {
+	switch cfg.Network {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+	default:
+		return fmt.Errorf("logger: unsupported syslog network %q", cfg.Network)
+	}
+	if cfg.Address == "" {
+		return fmt.Errorf("logger: syslog address is required")
+	}
+	if cfg.Facility < 0 || cfg.Facility > 23 {
+		return fmt.Errorf("logger: invalid syslog facility %d", cfg.Facility)
+	}
+	return nil
+}
+
+// newSyslogCore returns the core and starts its sender, which is stopped by
+// closing the returned closer. cfg must have been validated.
+func newSyslogCore(cfg SyslogConfig, enc zapcore.Encoder, enabler zapcore.LevelEnabler) (*syslogCore, closerFunc) {
+	facility := cfg.Facility
+	if facility == 0 {
+		facility = 1
+	}
+	tag := cfg.Tag
+	if tag == "" {
+		tag = filepath.Base(os.Args[0])
+	}
+	tag = strings.Join(strings.Fields(tag), "_")
+	if len(tag) > syslogMaxAppName {
+		tag = tag[:syslogMaxAppName]
+	}
+	queueSize := cfg.QueueSize
+	if queueSize <= 0 {
+		queueSize = syslogQueueSize
+	}
+
+	sender := &syslogSender{
+		network:    cfg.Network,
+		address:    cfg.Address,
+		dropOldest: cfg.DropOldest,
+		queue:      make(chan []byte, queueSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go sender.run()
+
+	core := &syslogCore{
+		LevelEnabler: enabler,
+		enc:          enc,
+		sender:       sender,
+		facility:     facility,
+		header:       fmt.Sprintf(" %s %s %d - - ", hostname(), tag, os.Getpid()),
+		octet:        strings.HasPrefix(cfg.Network, "tcp"),
+	}
+	// stopping is idempotent, so that a closed logger can be closed again.
+	var once sync.Once
+	stop := func() error {
+		once.Do(func() { close(sender.stop) })
+		<-sender.done
+		return nil
+	}
+	return core, stop
+}
+
+// With adds fields to a copy of the core.
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(clone.enc)
+	}
+	return &clone
+}
+
+// Check adds the core if the entry is enabled.
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write formats the entry and queues it, it never blocks on the network.
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+
+	var b strings.Builder
+	b.WriteString("<")
+	b.WriteString(strconv.Itoa(c.facility*8 + syslogSeverity(ent.Level)))
+	b.WriteString(">1 ")
+	b.WriteString(ent.Time.Format(syslogTimeFormat))
+	b.WriteString(c.header)
+	b.WriteString(strings.TrimRight(buf.String(), "\n"))
+	msg := b.String()
+
+	if c.octet {
+		msg = strconv.Itoa(len(msg)) + " " + msg
+	}
+	c.sender.send([]byte(msg))
+	return nil
+}
+
+// Sync is a no-op, messages are sent in the background.
+func (c *syslogCore) Sync() error {
+	return nil
+}
+
+// syslogSeverity maps the level to the severity defined by RFC 5424.
+func syslogSeverity(level zapcore.Level) int {
+	switch level {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// syslogSender sends the queued messages over a connection which is redialed
+// whenever it fails.
+type syslogSender struct {
+	network    string
+	address    string
+	dropOldest bool
+
+	queue chan []byte
+	stop  chan struct{}
+	done  chan struct{}
+	conn  net.Conn
+}
+
+// send queues msg, one message is dropped if the queue is full.
+func (s *syslogSender) send(msg []byte) {
+	select {
+	case s.queue <- msg:
+		return
+	default:
+	}
+
+	if !s.dropOldest {
+		return
+	}
+	select {
+	case <-s.queue:
+	default:
+	}
+	select {
+	case s.queue <- msg:
+	default:
+	}
+}
+
+// run sends the queued messages until the sender is stopped, the messages
+// left in the queue are sent if the connection is still up by then.
+func (s *syslogSender) run() {
+	defer close(s.done)
+	defer func() {
+		if s.conn != nil {
+			s.conn.Close()
+		}
+	}()
+
+	for {
+		select {
+		case <-s.stop:
+			s.drain()
+			return
+		case msg := <-s.queue:
+			if !s.deliver(msg) {
+				return
+			}
+		}
+	}
+}
+
+// deliver writes msg, it retries with backoff until it succeeds or the sender
+// is stopped, which is reported by returning false.
+func (s *syslogSender) deliver(msg []byte) bool {
+	backoff := syslogMinBackoff
+	for {
+		if s.conn == nil {
+			conn, err := net.DialTimeout(s.network, s.address, syslogDialTimeout)
+			if err == nil {
+				s.conn = conn
+			}
+		}
+
+		if s.conn != nil {
+			if s.write(msg) == nil {
+				return true
+			}
+			s.conn.Close()
+			s.conn = nil
+		}
+
+		select {
+		case <-s.stop:
+			return false
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > syslogMaxBackoff {
+			backoff = syslogMaxBackoff
+		}
+	}
+}
+
+// drain sends the queued messages without reconnecting.
+func (s *syslogSender) drain() {
+	for {
+		select {
+		case msg := <-s.queue:
+			if s.conn == nil || s.write(msg) != nil {
+				return
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (s *syslogSender) write(msg []byte) error {
+	s.conn.SetWriteDeadline(time.Now().Add(syslogWriteTimeout))
+	_, err := s.conn.Write(msg)
+	return err
+}
@@ -0,0 +1,98 @@
+package logger
+
+import (
+	"unicode/utf8"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const truncatedSuffix = "...(truncated)"
+
+// truncateCore truncates the message and the string and bytes fields longer
+// than max bytes, marking them with the "...(truncated)" suffix.
+type truncateCore struct {
+	zapcore.Core
+	max int
+}
+
+func newTruncateCore(core zapcore.Core, max int) *truncateCore {
+	return &truncateCore{Core: core, max: max}
+}
+
+// With adds the truncated fields to the core.
+func (c *truncateCore) With(fields []zapcore.Field) zapcore.Core {
+	return &truncateCore{Core: c.Core.With(c.truncate(fields)), max: c.max}
+}
+
+// Check adds the core itself rather than the wrapped one, so that Write gets
+// the fields.
+func (c *truncateCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+// Write writes the entry with the message and the fields truncated.
+func (c *truncateCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	if len(ent.Message) > c.max {
+		ent.Message = truncateString(ent.Message, c.max)
+	}
+	return c.Core.Write(ent, c.truncate(fields))
+}
+
+// truncate returns fields with the long values truncated, fields is copied
+// rather than modified in place since it is owned by the caller.
+func (c *truncateCore) truncate(fields []zapcore.Field) []zapcore.Field {
+	var truncated []zapcore.Field
+	for i, f := range fields {
+		switch f.Type {
+		case zapcore.StringType:
+			if len(f.String) <= c.max {
+				continue
+			}
+			f.String = truncateString(f.String, c.max)
+		case zapcore.ByteStringType, zapcore.BinaryType:
+			b, ok := f.Interface.([]byte)
+			if !ok || len(b) <= c.max {
+				continue
+			}
+			// the byte strings are UTF-8 text, while the binary data is
+			// arbitrary.
+			n := c.max
+			if f.Type == zapcore.ByteStringType {
+				n = runeBoundary(b, n)
+			}
+			f.Interface = append(append(make([]byte, 0, n+len(truncatedSuffix)), b[:n]...), truncatedSuffix...)
+		default:
+			continue
+		}
+
+		if truncated == nil {
+			truncated = make([]zapcore.Field, len(fields))
+			copy(truncated, fields)
+		}
+		truncated[i] = f
+	}
+
+	if truncated == nil {
+		return fields
+	}
+	return truncated
+}
+
+// truncateString cuts s to at most max bytes without splitting a rune, and
+// appends the suffix.
+func truncateString(s string, max int) string {
+	return s[:runeBoundary([]byte(s[:max+1]), max)] + truncatedSuffix
+}
+
+// runeBoundary returns the largest n <= max at which b can be cut without
+// splitting a rune, b has to be longer than max.
+func runeBoundary(b []byte, max int) int {
+	n := max
+	for n > 0 && !utf8.RuneStart(b[n]) {
+		n--
+	}
+	return n
+}
@@ -0,0 +1,66 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+	"unicode/utf8"
+
+	"go.uber.org/zap"
+)
+
+func TestTruncateString(t *testing.T) {
+	for _, tt := range []struct {
+		s    string
+		max  int
+		want string
+	}{
+		{"hello", 3, "hel"},
+		{"héllo", 1, "h"},
+		{"héllo", 2, "h"}, // é is 2 bytes, [1:3].
+		{"héllo", 3, "hé"},
+		{"日本語", 2, ""},
+		{"日本語", 4, "日"},
+		{"日本語", 6, "日本"},
+		{"a😀b", 4, "a"}, // 😀 is 4 bytes, [1:5].
+		{"a😀b", 5, "a😀"},
+	} {
+		got := truncateString(tt.s, tt.max)
+		if got != tt.want+truncatedSuffix {
+			t.Errorf("truncateString(%q, %d) = %q, want %q", tt.s, tt.max, got, tt.want+truncatedSuffix)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("truncateString(%q, %d) = %q, not valid UTF-8", tt.s, tt.max, got)
+		}
+		if n := runeBoundary([]byte(tt.s), tt.max); n != len(tt.want) {
+			t.Errorf("runeBoundary(%q, %d) = %d, want %d", tt.s, tt.max, n, len(tt.want))
+		}
+	}
+}
+
+func TestTruncateCore(t *testing.T) {
+	var buf bytes.Buffer
+	l := New(Options{Writer: &buf, MaxFieldBytes: 4})
+	l.With(zap.String("user", "José García")).Desugar().Info("日本語のログ",
+		zap.String("short", "日"),
+		zap.ByteString("bytes", []byte("abcñ")),
+		zap.Binary("binary", []byte{0xe6, 0x97, 0xa5, 0xe6, 0x9c}),
+	)
+
+	// the binary data is arbitrary, so it is cut at max bytes even within a
+	// rune.
+	binary := append([]byte{0xe6, 0x97, 0xa5, 0xe6}, truncatedSuffix...)
+	want := map[string]interface{}{
+		"msg":    "日" + truncatedSuffix,
+		"user":   "Jos" + truncatedSuffix, // é is 2 bytes, [3:5].
+		"short":  "日",
+		"bytes":  "abc" + truncatedSuffix, // ñ is 2 bytes, [3:5].
+		"binary": base64.StdEncoding.EncodeToString(binary),
+	}
+	line := decodeLines(t, &buf)[0]
+	for k, v := range want {
+		if line[k] != v {
+			t.Errorf("%s = %v, want %v", k, line[k], v)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// stderr is the default error output of loggers, as it is in zap.
+var stderr = zapcore.Lock(os.Stderr)
+
+// closerFunc is an adapter to allow the use of ordinary functions as io.Closer.
+type closerFunc func() error
+
+// Close calls f().
+func (f closerFunc) Close() error {
+	return f()
+}
+
+// consoleSyncer wraps the stdout/stderr file. Syncing a terminal or a pipe
+// returns EINVAL or ENOTTY on some platforms, which is not a real failure.
+type consoleSyncer struct {
+	*os.File
+}
+
+// Sync commits the file and swallows the errors described above.
+func (s consoleSyncer) Sync() error {
+	err := s.File.Sync()
+	if isUnsyncable(err) {
+		return nil
+	}
+	return err
+}
+
+// isTerminal reports whether w is stdout or stderr attached to a terminal.
+func isTerminal(w zapcore.WriteSyncer) bool {
+	s, ok := w.(consoleSyncer)
+	if !ok {
+		return false
+	}
+	fi, err := s.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// outputSwitch holds the writer which replaces the primary output of a logger
+// after SetOutput is called.
+type outputSwitch struct {
+	mu sync.RWMutex
+	w  zapcore.WriteSyncer
+}
+
+// set replaces the output with w, nil restores the original one.
+func (s *outputSwitch) set(w io.Writer) {
+	var ws zapcore.WriteSyncer
+	if w != nil {
+		// the writes are serialized as they are for Options.Writer.
+		ws = zapcore.Lock(zapcore.AddSync(w))
+	}
+	s.mu.Lock()
+	s.w = ws
+	s.mu.Unlock()
+}
+
+func (s *outputSwitch) get() zapcore.WriteSyncer {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.w
+}
+
+// switchedSyncer writes to the writer of the switch if there is one, or to
+// the original write syncer otherwise.
+type switchedSyncer struct {
+	zapcore.WriteSyncer
+	sw *outputSwitch
+}
+
+// Write writes p to the current output.
+func (s switchedSyncer) Write(p []byte) (int, error) {
+	if w := s.sw.get(); w != nil {
+		return w.Write(p)
+	}
+	return s.WriteSyncer.Write(p)
+}
+
+// Sync syncs the current output.
+func (s switchedSyncer) Sync() error {
+	if w := s.sw.get(); w != nil {
+		return w.Sync()
+	}
+	return s.WriteSyncer.Sync()
+}
+
+// reopenableFile writes to a file given by the user, which can be swapped for
+// the file newly opened by the same name.
+type reopenableFile struct {
+	mu    sync.Mutex
+	file  *os.File
+	owned bool // the file is opened by reopen rather than given.
+}
+
+// Write writes p to the current file.
+func (f *reopenableFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Write(p)
+}
+
+// Sync commits the current file.
+func (f *reopenableFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.file.Sync()
+}
+
+// Close closes the current file if it was opened by reopen.
+func (f *reopenableFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.owned {
+		return nil
+	}
+	return f.file.Close()
+}
+
+// reopen opens the file by its name again and swaps it in.
+func (f *reopenableFile) reopen() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	file, err := os.OpenFile(f.file.Name(), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if f.owned {
+		f.file.Close()
+	}
+	f.file, f.owned = file, true
+	return nil
+}
+
+// shardedSyncer writes the entries to the shards in turn, every write is taken
+// as one entry since the cores write an entry at a time.
+type shardedSyncer struct {
+	shards []zapcore.WriteSyncer
+	next   uint32
+}
+
+// shardName inserts the index of the shard before the extension of filename.
+func shardName(filename string, i int) string {
+	ext := filepath.Ext(filename)
+	return fmt.Sprintf("%s.%d%s", strings.TrimSuffix(filename, ext), i, ext)
+}
+
+// Write writes p to the next shard.
+func (s *shardedSyncer) Write(p []byte) (int, error) {
+	i := (atomic.AddUint32(&s.next, 1) - 1) % uint32(len(s.shards))
+	return s.shards[i].Write(p)
+}
+
+// Sync syncs all the shards.
+func (s *shardedSyncer) Sync() error {
+	var err error
+	for _, shard := range s.shards {
+		if serr := shard.Sync(); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	return err
+}
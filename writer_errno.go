@@ -0,0 +1,15 @@
+//go:build !plan9
+// +build !plan9
+
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isUnsyncable reports whether err is returned by syncing a file which can't
+// be synced, like a terminal or a pipe.
+func isUnsyncable(err error) bool {
+	return errors.Is(err, syscall.EINVAL) || errors.Is(err, syscall.ENOTTY)
+}
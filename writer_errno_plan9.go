@@ -0,0 +1,12 @@
+package logger
+
+import (
+	"errors"
+	"syscall"
+)
+
+// isUnsyncable reports whether err is returned by syncing a file which can't
+// be synced, plan9 has no ENOTTY.
+func isUnsyncable(err error) bool {
+	return errors.Is(err, syscall.EINVAL)
+}